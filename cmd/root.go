@@ -1,10 +1,14 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"maps"
+	"math"
 	"os"
-	"os/exec" // Added for config command
+	"os/exec" // Used to launch $EDITOR
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -12,6 +16,7 @@ import (
 	"grain/internal/cli"
 	"grain/internal/config"
 	"grain/internal/data"
+	"grain/internal/ics"
 	"grain/internal/logic"
 	"grain/internal/timeutil"
 
@@ -48,18 +53,17 @@ and spend them on breaks. Simple, local, and calm.`,
 			}
 		}
 		// Default action: log study credits
-		if err := logic.AddLog(&appState, data.LogTypeStudy, amount, time.Now()); err != nil {
-			errLog(err)
-			return
-		}
-		if err := data.SaveState(dataPath, &appState); err != nil {
-			errLog(err)
-			return
-		}
-		fmt.Printf("✨ +%d study credits logged. Keep it rolling!\n", amount)
+		logStudy(amount)
 	},
 }
 
+// Flags shared by the study logging commands
+var (
+	tagFlag    string // --tag subject
+	energyFlag int    // --energy rating, 0 when not given
+	focusFlag  int    // --focus rating, 0 when not given
+)
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() error {
@@ -78,6 +82,39 @@ func init() {
 	addCommands()                          // Add commands after initialization setup
 }
 
+// recoverState replaces a corrupted data file: with the newest valid backup if the user agrees,
+// otherwise with whatever can be salvaged from the corrupted file, read-only.
+func recoverState(corruptFile string) data.AppState {
+	if backupFile, err := data.LatestValidBackup(backupDir); err == nil {
+		if cli.PromptConfirmation(fmt.Sprintf("♻️  Restore the newest valid backup, %s?\nType \"yes\" to confirm:", filepath.Base(backupFile))) {
+			if err := data.RestoreData(dataPath, backupFile); err != nil {
+				errLog(err)
+			}
+			state, err := data.LoadState(dataPath, cfg)
+			if err != nil {
+				errLog(fmt.Errorf("failed to load restored state: %w", err))
+			}
+			fmt.Printf("♻️ Data restored from %s.\n", filepath.Base(backupFile))
+			return state
+		}
+	}
+
+	state, salvage, err := data.SalvageState(corruptFile, cfg)
+	if err != nil {
+		errLog(err)
+	}
+	fmt.Printf("🩹 Read-only mode: salvaged %d days from %s", salvage.Days, filepath.Base(corruptFile))
+	if salvage.Skipped > 0 {
+		fmt.Printf(", %d unreadable days left out", salvage.Skipped)
+	}
+	if salvage.Partial {
+		fmt.Print(", anything after the damage is missing")
+	}
+	fmt.Println(".")
+	fmt.Println("   Nothing is saved until you restore: 'grain restore' for the newest backup, or 'grain backup' then 'grain restore' to keep what was salvaged.")
+	return state
+}
+
 // loadConfigAndState loads the application configuration and data state.
 // It's called by cobra.OnInitialize.
 func loadConfigAndState() {
@@ -91,6 +128,13 @@ func loadConfigAndState() {
 	if err != nil {
 		errLog(fmt.Errorf("failed to load config: %w", err))
 	}
+	if fromWeek, edited := logic.ReconcileGoals(&cfg); edited {
+		// The goal history would otherwise keep overriding a hand-edited weekly_goal or break_start
+		if err := config.SaveConfig(configPath, cfg); err != nil {
+			errLog(fmt.Errorf("failed to save updated config file: %w", err))
+		}
+		fmt.Printf("🎯 weekly_goal and break_start were edited in config.json, so they now apply from week %s on\n", fromWeek)
+	}
 
 	// Check if data file exists before loading state
 	firstRun := false
@@ -99,12 +143,41 @@ func loadConfigAndState() {
 	}
 
 	appState, err = data.LoadState(dataPath, cfg) // Pass loaded config to state
-	if err != nil {
+	var corrupt *data.CorruptDataError
+	if errors.As(err, &corrupt) {
+		corruptFile, err := data.QuarantineData(dataPath)
+		if err != nil {
+			errLog(err)
+		}
+		fmt.Printf("⚠️  %v\n⚠️  Moved it aside to %s\n", corrupt, filepath.Base(corruptFile))
+		appState, firstRun = recoverState(corruptFile), false
+	} else if err != nil {
 		errLog(fmt.Errorf("failed to load state: %w", err))
+	} else if corruptFile, found := data.LatestCorruptFile(dataPath); firstRun && found {
+		// The data file was moved aside on an earlier run and nothing has replaced it yet
+		appState, firstRun = recoverState(corruptFile), false
+	}
+
+	// doctor has to see data.json as stored, so nothing is recalculated or saved before it runs
+	if !firstRun && runningDoctor() {
+		return
 	}
 
 	// Perform initial calculations or ensure stats are up-to-date
+	storedSurplus, storedBest := appState.WeeklySurplus, appState.BestSurplus
 	logic.RecalculateOverallStats(&appState) // Recalculate streak, best surplus based on loaded data
+	// Config edits since the last save change the surplus, so store the fresh values
+	changed := !maps.Equal(storedSurplus, appState.WeeklySurplus) || storedBest != appState.BestSurplus
+	if freeze := logic.ApplyAutoFreeze(&appState); freeze != nil {
+		fmt.Printf("🧊 Missed week %s, so a streak freeze was used automatically. Your streak is safe.\n", freeze.Week)
+		changed = true
+	}
+	// In read-only mode nothing is saved; the freeze is applied again once the data is restored
+	if changed && !firstRun && !appState.ReadOnly {
+		if err := data.SaveState(dataPath, &appState); err != nil {
+			errLog(fmt.Errorf("failed to save recalculated state: %w", err))
+		}
+	}
 	// No need to explicitly save here unless firstRun caused changes needing immediate persistence
 	// Save operations happen within commands after modification.
 	if firstRun {
@@ -115,10 +188,85 @@ func loadConfigAndState() {
 	}
 }
 
+// runningDoctor reports whether the command line runs grain doctor.
+func runningDoctor() bool {
+	cmd, _, err := rootCmd.Find(os.Args[1:])
+	return err == nil && cmd.Name() == "doctor"
+}
+
+// logStudy records study credits now, with the --tag flag if given, and saves the state.
+func logStudy(amount int) {
+	entry := data.Log{
+		Timestamp: timeutil.Now(appState.Config),
+		Amount:    amount,
+		Tag:       strings.ToLower(strings.TrimSpace(tagFlag)),
+		Energy:    energyFlag,
+		Focus:     focusFlag,
+	}
+	if err := logic.AddStudy(&appState, entry); err != nil {
+		errLog(err)
+		return
+	}
+	if err := data.SaveState(dataPath, &appState); err != nil {
+		errLog(err)
+		return
+	}
+	if entry.Tag != "" {
+		fmt.Printf("✨ +%d %s study credits logged. Keep it rolling!\n", amount, entry.Tag)
+		return
+	}
+	fmt.Printf("✨ +%d study credits logged. Keep it rolling!\n", amount)
+}
+
+// findEditor returns $EDITOR, falling back to common editors found in PATH.
+func findEditor() (string, error) {
+	if editor := os.Getenv("EDITOR"); editor != "" {
+		return editor, nil
+	}
+	// Simple default lookup
+	for _, editor := range []string{"vim", "nano", "code"} { // code is VS Code
+		if _, err := exec.LookPath(editor); err == nil {
+			return editor, nil
+		}
+	}
+	return "", fmt.Errorf("EDITOR environment variable not set and common editors (vim, nano, code) not found.")
+}
+
+// runEditor opens path in editor and waits for it to close.
+func runEditor(editor, path string) error {
+	editorCmd := exec.Command(editor, path)
+	editorCmd.Stdin = os.Stdin
+	editorCmd.Stdout = os.Stdout
+	editorCmd.Stderr = os.Stderr
+
+	if err := editorCmd.Run(); err != nil {
+		return fmt.Errorf("failed to open editor '%s': %w\nCheck if '%s' is in your PATH.", editor, err, editor)
+	}
+	return nil
+}
+
+// weekStatus returns the short status marker shown for a week in history views.
+func weekStatus(summary logic.WeekSummary, inProgress bool) string {
+	switch {
+	case summary.FullyOff():
+		return "🏖️"
+	case summary.Frozen:
+		return "🧊"
+	case inProgress:
+		return "⏳"
+	case summary.HasLogs && summary.Study >= summary.Goal:
+		return "✅"
+	default:
+		return "❌"
+	}
+}
+
 // addCommands registers all subcommands to the root command.
 func addCommands() {
 	// Define flags
 	var sinceFlag string
+	var tzFlag string
+	var borrowFlag bool
 
 	// --- Add Study/Break Logging Commands ---
 	studyCmd := &cobra.Command{
@@ -136,15 +284,7 @@ func addCommands() {
 					return
 				}
 			}
-			if err := logic.AddLog(&appState, data.LogTypeStudy, amount, time.Now()); err != nil {
-				errLog(err)
-				return
-			}
-			if err := data.SaveState(dataPath, &appState); err != nil {
-				errLog(err)
-				return
-			}
-			fmt.Printf("✨ +%d study credits logged. Keep it rolling!\n", amount)
+			logStudy(amount)
 		},
 	}
 
@@ -164,14 +304,9 @@ func addCommands() {
 				}
 			}
 
-			// Check if enough break credits are available before logging
-			_, _, breaksAvailable := logic.CalculateCurrentWeekStats(&appState)
-			if amount > breaksAvailable {
-				errLog(fmt.Errorf("not enough break credits (need %d, have %d)", amount, breaksAvailable))
-				return
-			}
-
-			if err := logic.AddLog(&appState, data.LogTypeBreak, amount, time.Now()); err != nil {
+			// The balance check (and borrowing) lives in logic so every caller enforces the same policy
+			borrowed, err := logic.AddBreak(&appState, amount, timeutil.Now(appState.Config), borrowFlag)
+			if err != nil {
 				errLog(err)
 				return
 			}
@@ -179,10 +314,22 @@ func addCommands() {
 				errLog(err)
 				return
 			}
+			if borrowed > 0 {
+				fmt.Printf("🏦 Borrowed %d break credits. They'll be repaid from the study credits you log next.\n", borrowed)
+			}
 			fmt.Printf("🍵 -%d break credit logged. Breathe easy.\n", amount)
 		},
 	}
 
+	breakCmd.Flags().BoolVar(&borrowFlag, "borrow", false, "Borrow break credits against future study if the balance runs out")
+
+	rootCmd.Flags().StringVar(&tagFlag, "tag", "", "Tag the study credits with a subject (e.g. physics)")
+	studyCmd.Flags().StringVar(&tagFlag, "tag", "", "Tag the study credits with a subject (e.g. physics)")
+	rootCmd.Flags().IntVar(&energyFlag, "energy", 0, "Rate your energy during the session (1-5)")
+	studyCmd.Flags().IntVar(&energyFlag, "energy", 0, "Rate your energy during the session (1-5)")
+	rootCmd.Flags().IntVar(&focusFlag, "focus", 0, "Rate your focus during the session (1-5)")
+	studyCmd.Flags().IntVar(&focusFlag, "focus", 0, "Rate your focus during the session (1-5)")
+
 	rootCmd.AddCommand(studyCmd)
 	rootCmd.AddCommand(breakCmd)
 
@@ -190,38 +337,53 @@ func addCommands() {
 	logCmd := &cobra.Command{
 		Use:   "log",
 		Short: "🗓️  View log entries",
-		Long:  "View log entries. By default, shows today. Use --since to specify a start date (e.g., 'yesterday', 'monday', 'YYYY-MM-DD').",
+		Long:  "View log entries. By default, shows today. Use --since to specify a start date (e.g., 'yesterday', 'week', 'monday', 'YYYY-MM-DD'). 'week' starts on week_start, 'monday' on the most recent Monday.",
 		Args:  cobra.NoArgs,
 		Run: func(cmd *cobra.Command, args []string) {
-			now := time.Now()
-			startDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()) // Default to start of today
-			endDate := startDate.Add(24 * time.Hour)                                               // Default to end of today
+			// Entries are grouped in the home time zone; --tz only changes how clock times are shown
+			var displayLoc *time.Location
+			switch strings.ToLower(tzFlag) {
+			case "", "home":
+				displayLoc = timeutil.Location(appState.Config)
+			case "local":
+				displayLoc = time.Local
+			default:
+				errLog(fmt.Errorf("invalid --tz value: '%s'. Use 'home' or 'local'", tzFlag))
+				return
+			}
+
+			now := timeutil.Now(appState.Config)
+			today := timeutil.LogicalDay(now, appState.Config) // Days roll over at day_starts_at, not midnight
+			startDate := today                                 // Default to today
+			endDate := today
 
-			headerDateStr := now.Format("Jan 2")
+			headerDateStr := today.Format("Jan 2")
 
 			if sinceFlag != "" {
 				sinceFlag = strings.ToLower(sinceFlag)
 				if sinceFlag == "today" {
 					// Already defaulted to today
 				} else if sinceFlag == "yesterday" {
-					startDate = startDate.AddDate(0, 0, -1)
+					startDate = today.AddDate(0, 0, -1)
+					endDate = startDate
 					headerDateStr = startDate.Format("Jan 2")
-					endDate = startDate.Add(24 * time.Hour)
-				} else if sinceFlag == "monday" {
-					startOfWeek, _ := timeutil.GetWeekBounds(now)
+				} else if sinceFlag == "week" {
+					startOfWeek, _ := timeutil.GetWeekBounds(now, appState.Config)
 					startDate = startOfWeek
 					headerDateStr = fmt.Sprintf("Week of %s", startDate.Format("Jan 2"))
-					endDate = now // Show up to current time if filtering from Monday
+				} else if sinceFlag == "monday" {
+					// The most recent Monday, today included, whatever day the week starts on
+					startDate = today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+					headerDateStr = fmt.Sprintf("Since %s", startDate.Format("Mon, Jan 2"))
 				} else {
 					// Try parsing as YYYY-MM-DD
-					parsedDate, err := time.Parse(data.DateFormat, sinceFlag)
+					parsedDate, err := timeutil.ParseDate(sinceFlag, appState.Config)
 					if err != nil {
-						errLog(fmt.Errorf("invalid --since value: '%s'. Use 'today', 'yesterday', 'monday', or 'YYYY-MM-DD'", sinceFlag))
+						errLog(fmt.Errorf("invalid --since value: '%s'. Use 'today', 'yesterday', 'week', 'monday' or 'YYYY-MM-DD'", sinceFlag))
 						return
 					}
-					startDate = time.Date(parsedDate.Year(), parsedDate.Month(), parsedDate.Day(), 0, 0, 0, 0, now.Location())
+					startDate = parsedDate
 					headerDateStr = fmt.Sprintf("Since %s", startDate.Format("Jan 2"))
-					endDate = now // Show up to current time if filtering from a specific date
 				}
 			}
 
@@ -230,27 +392,22 @@ func addCommands() {
 			foundLogs := false
 			totalStudy := 0
 			totalBreaks := 0
+			first := startDate.Format(data.DateFormat)
+			last := endDate.Format(data.DateFormat)
 
-			// Iterate through all days and logs, filtering by date range
+			// Iterate through all days and logs, filtering by logical date range [first, last]
 			for _, day := range appState.Logs {
-				dayDate, err := time.Parse(data.DateFormat, day.Date)
-				if err != nil {
-					continue // Skip malformed dates in data
-				}
-
-				// Check if the day is within the filter range [startDate, endDate)
-				if (dayDate.Equal(startDate) || dayDate.After(startDate)) && dayDate.Before(endDate) {
-					for _, log := range day.Logs {
-						// Also check log timestamp is within range (useful for multi-day filters)
-						if (log.Timestamp.Equal(startDate) || log.Timestamp.After(startDate)) && log.Timestamp.Before(endDate) {
-							fmt.Println(cli.FormatLogEntry(log))
-							foundLogs = true
-							if log.Type == data.LogTypeStudy {
-								totalStudy += log.Amount
-							} else {
-								totalBreaks += log.Amount
-							}
-						}
+				if day.Date < first || day.Date > last {
+					continue
+				}
+				for _, log := range day.Logs {
+					log.Timestamp = log.Timestamp.In(displayLoc)
+					fmt.Println(cli.FormatLogEntry(log))
+					foundLogs = true
+					if log.Type == data.LogTypeStudy {
+						totalStudy += log.Amount
+					} else {
+						totalBreaks += log.Amount
 					}
 				}
 			}
@@ -264,38 +421,61 @@ func addCommands() {
 		},
 	}
 	// Add the flag to the log command
-	logCmd.Flags().StringVar(&sinceFlag, "since", "", "Show logs since a specific time (e.g., 'today', 'yesterday', 'monday', 'YYYY-MM-DD')")
+	logCmd.Flags().StringVar(&sinceFlag, "since", "", "Show logs since a specific time (e.g., 'today', 'yesterday', 'week', 'monday', 'YYYY-MM-DD')")
+	logCmd.Flags().StringVar(&tzFlag, "tz", "home", "Time zone for displayed times: 'home' (config timezone) or 'local' (this machine)")
 
 	weekCmd := &cobra.Command{
 		Use:   "week",
 		Short: "📊 View current weekly overview",
 		Args:  cobra.NoArgs,
 		Run: func(cmd *cobra.Command, args []string) {
-			now := time.Now()
-			startOfWeek, _ := timeutil.GetWeekBounds(now)
 			// Recalculate just before display to ensure freshness
-			studyCredits, _, breaksAvailable := logic.CalculateCurrentWeekStats(&appState)
+			logic.CalculateCurrentWeekStats(&appState)
 			logic.RecalculateOverallStats(&appState) // Ensure streak is also fresh
+			summary := logic.CurrentWeekSummary(&appState)
 
-			fmt.Println(cli.FormatHeader(fmt.Sprintf("📊 Week of %s", startOfWeek.Format("Jan 2"))))
-			fmt.Printf("🧠 Study     ▸ %d / %d\n", studyCredits, appState.Config.WeeklyGoal)
-			fmt.Printf("💤 Breaks    ▸ %d / %d\n", breaksAvailable, appState.Config.BreakStart)
-
-			// Explicitly get current week surplus from the map
-			currentWeekID := timeutil.GetWeekID(now)
-			currentSurplus := appState.WeeklySurplus[currentWeekID]
-			// Ensure surplus calculation matches expectation (non-negative, based on goal)
-			if studyCredits >= appState.Config.WeeklyGoal {
-				calculatedSurplus := (studyCredits - appState.Config.WeeklyGoal) * 2
-				// Use calculated surplus if different from stored, though CalculateCurrentWeekStats should update it.
-				// This is more for display consistency.
-				currentSurplus = calculatedSurplus
-			} else {
-				currentSurplus = 0 // Don't display negative surplus
+			fmt.Println(cli.FormatHeader(fmt.Sprintf("📊 Week of %s", summary.Start.Format("Jan 2"))))
+			fmt.Printf("🧠 Study     ▸ %d / %d\n", summary.Study, summary.Goal)
+			if summary.DaysOff > 0 {
+				fmt.Printf("🏖️  Days off  ▸ %d (goal prorated %d → %d)\n", summary.DaysOff, summary.FullGoal, summary.BaseGoal)
+			}
+			if summary.Debt > 0 {
+				prevID := timeutil.GetWeekIDForDate(summary.Start.AddDate(0, 0, -7), appState.Config)
+				fmt.Printf("🎯 Goal      ▸ %d = %d base + %d debt from %s\n", summary.Goal, summary.BaseGoal, summary.Debt, prevID)
+			}
+			// An outstanding loan shows up as a negative balance
+			loan := logic.OutstandingLoan(&appState)
+			fmt.Printf("💤 Breaks    ▸ %d / %d\n", summary.Available-loan, summary.Budget())
+			if loan > 0 {
+				fmt.Printf("🏦 Loan      ▸ %d owed (limit %d), repaid from upcoming study\n", loan, appState.Config.BorrowLimit)
+			}
+			if appState.Config.Rollover.Mode != data.RolloverNone {
+				fmt.Printf("↪️  Rollover  ▸ %d of %d carried from last week\n", summary.Carryover, summary.Budget())
+			}
+			fmt.Printf("✨ Surplus   ▸ %d\n", summary.Earned) // Break credits earned under the break rules
+			if summary.Bonus > 0 {
+				fmt.Printf("🎁 Bonus     ▸ %d rest-day credits (outside the goal)\n", summary.Bonus)
 			}
 
-			fmt.Printf("✨ Surplus   ▸ %d\n", currentSurplus)
+			// Forecast the rest of the week from the pace so far and past weekdays
+			projection := logic.ProjectWeek(&appState)
+			if projection.RemainingDays > 0 {
+				pace := "✅ on track"
+				if !projection.OnTrack {
+					pace = "⚠️  behind"
+				}
+				fmt.Printf("🔮 Projected ▸ %d / %d %s\n", projection.ProjectedStudy, summary.Goal, pace)
+				if projection.RequiredPerDay > 0 {
+					fmt.Printf("📐 Needed    ▸ %d per day over %d working days\n", projection.RequiredPerDay, projection.RemainingDays)
+				}
+				fmt.Printf("🌱 At pace   ▸ +%d surplus → +%d breaks\n", projection.ProjectedSurplus, projection.ProjectedBreaks)
+			}
 			fmt.Printf("🔥 Streak    ▸ %d weeks\n", appState.Streak)
+			if logic.HasReflection(&appState, summary.ID) {
+				fmt.Println("✍️  Reflect   ▸ written (grain reflect --show)")
+			} else {
+				fmt.Println("✍️  Reflect   ▸ not yet (grain reflect)")
+			}
 		},
 	}
 
@@ -310,38 +490,197 @@ func addCommands() {
 
 			fmt.Println(cli.FormatHeader("📈 Your Stats"))
 			fmt.Printf("🔁 Streak:         %d weeks\n", appState.Streak)
+			fmt.Printf("📅 Daily Streak:   %d days\n", appState.DailyStreak)
+			fmt.Printf("🥇 Longest Streak: %s\n", cli.FormatStreakRecord(appState.LongestStreak, "weeks"))
+			fmt.Printf("🥇 Longest Daily:  %s\n", cli.FormatStreakRecord(appState.LongestDaily, "days"))
+			if appState.Config.FreezesPerMonth > 0 {
+				fmt.Printf("🧊 Freezes Left:   %d this month (%d used in total)\n", logic.FreezesLeft(&appState, timeutil.Now(appState.Config)), len(appState.Freezes))
+			}
 			fmt.Printf("🏆 Best Surplus:   +%d\n", appState.BestSurplus)
 			fmt.Printf("📚 Total Study:    %d credits\n", totalStudy)
 			fmt.Printf("🍵 Total Breaks:   %d credits\n", totalBreaks)
 			fmt.Printf("🧾 Total Entries:  %d\n", totalEntries)
+
+			trend := logic.AnalyzeTrend(&appState)
+			if len(trend.Weeks) == 0 {
+				return
+			}
+			fmt.Printf("📊 4-Week Avg:     %.1f per week\n", trend.Average4)
+			fmt.Printf("📊 12-Week Avg:    %.1f per week\n", trend.Average12)
+			trendIcon := map[string]string{"up": "📈", "down": "📉", "flat": "➡️ "}[trend.Direction()]
+			fmt.Printf("%s Trend:          %s (%+.1f per week over the last %d weeks)\n", trendIcon, trend.Direction(), trend.Slope, trend.SlopeWeeks)
+			monthChange := ""
+			if trend.LastMonth > 0 {
+				monthChange = fmt.Sprintf(", %+.0f%%", float64(trend.ThisMonth-trend.LastMonth)/float64(trend.LastMonth)*100)
+			}
+			fmt.Printf("🗓️  This Month:     %d vs %d over the same %d days last month%s\n", trend.ThisMonth, trend.LastMonth, trend.MonthDays, monthChange)
+			if trend.SustainedDecline() {
+				fmt.Printf("⚠️  Sustained decline: %d weeks in a row below your 4-week average. Time to check in.\n", trend.DecliningFor)
+			}
+		},
+	}
+
+	recordsCmd := &cobra.Command{
+		Use:   "records",
+		Short: "🏆 Show your personal records and milestones",
+		Long: `Shows your personal records and lifetime milestones, recomputed from your logs every time,
+so undoing an entry also undoes any record it set.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			records := logic.ComputeRecords(&appState)
+			fmt.Println(cli.FormatHeader("🏆 Personal Records"))
+			if records.TotalStudy == 0 {
+				fmt.Println("No study logged yet. Your first record is one credit away.")
+				return
+			}
+			fmt.Printf("📅 Best Day:        %d credits on %s\n", records.BestDay.Value, records.BestDay.Date)
+			fmt.Printf("🗓️  Best Week:       %d credits in %s (week of %s)\n", records.BestWeek.Value, records.BestWeek.Week, records.BestWeek.Date)
+			if records.BestSurplus.Value > 0 {
+				fmt.Printf("✨ Best Surplus:    +%d breaks in %s\n", records.BestSurplus.Value, records.BestSurplus.Week)
+			}
+			fmt.Printf("⏱️  Biggest Session: %d credits on %s\n", records.LongestSession.Value, records.LongestSession.Date)
+			fmt.Printf("🔥 Longest Streak:  %s\n", cli.FormatStreakRecord(records.LongestStreak, "weeks"))
+			fmt.Printf("📆 Longest Daily:   %s\n", cli.FormatStreakRecord(records.LongestDaily, "days"))
+
+			fmt.Printf("\n🎖️  Milestones (%d credits so far)\n", records.TotalStudy)
+			for _, milestone := range records.Milestones {
+				if milestone.Date != "" {
+					fmt.Printf("  ✅ %5d credits on %s\n", milestone.Credits, milestone.Date)
+				}
+			}
+			if next, ok := records.NextMilestone(); ok {
+				fmt.Printf("  ⬜ %5d credits, %d to go\n", next.Credits, next.Credits-records.TotalStudy)
+			}
 		},
 	}
 
+	var historyWeeksFlag int
+	historyCmd := &cobra.Command{
+		Use:   "history",
+		Short: "📜 Show past weeks at a glance",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if historyWeeksFlag <= 0 {
+				errLog(fmt.Errorf("invalid --weeks value: %d. Please provide a positive number", historyWeeksFlag))
+				return
+			}
+			logic.RecalculateOverallStats(&appState)
+			current := logic.CurrentWeekSummary(&appState)
+
+			fmt.Println(cli.FormatHeader("📜 History"))
+			for i := 0; i < historyWeeksFlag; i++ {
+				summary := logic.SummarizeWeek(&appState, current.Start.AddDate(0, 0, -7*i))
+				fmt.Println(cli.FormatWeekRow(summary.ID, summary.Start, summary.Study, summary.Goal, weekStatus(summary, i == 0), summary.DaysOff, logic.HasReflection(&appState, summary.ID)))
+			}
+		},
+	}
+	historyCmd.Flags().IntVar(&historyWeeksFlag, "weeks", 8, "Number of weeks to show, newest first")
+
+	var heatmapWeeksFlag int
+	heatmapCmd := &cobra.Command{
+		Use:   "heatmap",
+		Short: "🟩 Show daily study as a heatmap, with days off and rest days marked",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if heatmapWeeksFlag <= 0 {
+				errLog(fmt.Errorf("invalid --weeks value: %d. Please provide a positive number", heatmapWeeksFlag))
+				return
+			}
+			fmt.Println(cli.FormatHeader("🟩 Heatmap"))
+			fmt.Println(cli.FormatHeatmap(logic.Heatmap(&appState, heatmapWeeksFlag)))
+		},
+	}
+	heatmapCmd.Flags().IntVar(&heatmapWeeksFlag, "weeks", 12, "Number of weeks to show, oldest on the left")
+
 	rootCmd.AddCommand(logCmd)
 	rootCmd.AddCommand(weekCmd)
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(heatmapCmd)
 	rootCmd.AddCommand(statsCmd)
+	rootCmd.AddCommand(recordsCmd)
 
 	// --- Add Goal Command ---
+	var goalWeekFlag string
+	var goalBreaksFlag int
 	goalCmd := &cobra.Command{
 		Use:   "goal [amount]",
 		Short: "🎯 View or set your weekly study goal",
-		Args:  cobra.MaximumNArgs(1),
+		Long: `View or set your weekly study goal. A new goal takes effect from the current week;
+earlier weeks keep being judged by the goal that applied to them.
+Use --week YYYY-WW to override the goal for a single week (0 removes the override),
+and --breaks to change the break credits granted each week.`,
+		Args: cobra.MaximumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) == 0 {
-				// View current goal
-				fmt.Printf("🎯 Current weekly study goal: %d credits\n", appState.Config.WeeklyGoal)
+			if len(args) == 0 && !cmd.Flags().Changed("breaks") {
+				// View current goal and its history
+				summary := logic.CurrentWeekSummary(&appState)
+				fmt.Printf("🎯 Current weekly study goal: %d credits (%d break credits)\n", summary.FullGoal, summary.BreakStart)
+				// What this week actually asks for, once days off and debt are taken into account
+				if summary.DaysOff > 0 {
+					fmt.Printf("   this week: prorated to %d for %d days off\n", summary.BaseGoal, summary.DaysOff)
+				}
+				if summary.Debt > 0 {
+					fmt.Printf("   this week: %d = %d base + %d debt\n", summary.Goal, summary.BaseGoal, summary.Debt)
+				}
+				for _, change := range appState.Config.GoalHistory {
+					from := change.From
+					if from == "" {
+						from = "the beginning"
+					}
+					if change.From > summary.End.Format(data.DateFormat) {
+						fmt.Printf("   from %s (upcoming): %d study, %d breaks\n", from, change.WeeklyGoal, change.BreakStart)
+						continue
+					}
+					fmt.Printf("   since %s: %d study, %d breaks\n", from, change.WeeklyGoal, change.BreakStart)
+				}
+				weekIDs := []string{}
+				for weekID := range appState.Config.WeekGoals {
+					weekIDs = append(weekIDs, weekID)
+				}
+				sort.Strings(weekIDs)
+				for _, weekID := range weekIDs {
+					fmt.Printf("   week %s: %d study (override)\n", weekID, appState.Config.WeekGoals[weekID])
+				}
 				return
 			}
 
-			// Set new goal
-			newGoal, err := strconv.Atoi(args[0])
-			if err != nil || newGoal <= 0 {
-				errLog(fmt.Errorf("invalid goal amount: '%s'. Please provide a positive number", args[0]))
-				return
+			newGoal := 0
+			if len(args) == 1 {
+				var err error
+				newGoal, err = strconv.Atoi(args[0])
+				if err != nil || newGoal < 0 || (newGoal == 0 && goalWeekFlag == "") {
+					errLog(fmt.Errorf("invalid goal amount: '%s'. Please provide a positive number", args[0]))
+					return
+				}
 			}
 
-			// Update the config in memory
-			appState.Config.WeeklyGoal = newGoal
+			if goalWeekFlag != "" {
+				// Override a single week's goal
+				if len(args) == 0 {
+					errLog(fmt.Errorf("please provide the goal for week %s (0 removes the override)", goalWeekFlag))
+					return
+				}
+				if _, err := timeutil.WeekStartFromID(goalWeekFlag, appState.Config); err != nil {
+					errLog(err)
+					return
+				}
+				logic.SetWeekGoal(&appState.Config, goalWeekFlag, newGoal)
+			} else {
+				// Record an effective-dated change starting this week
+				currentStart, _ := timeutil.GetWeekBounds(timeutil.Now(appState.Config), appState.Config)
+				goal, breakStart := logic.ScheduledGoals(appState.Config, currentStart)
+				if newGoal > 0 {
+					goal = newGoal
+				}
+				if cmd.Flags().Changed("breaks") {
+					if goalBreaksFlag < 0 {
+						errLog(fmt.Errorf("invalid break credits: %d. Please provide zero or a positive number", goalBreaksFlag))
+						return
+					}
+					breakStart = goalBreaksFlag
+				}
+				logic.SetGoals(&appState.Config, currentStart, goal, breakStart)
+			}
 
 			// Save the updated config to file
 			if err := config.SaveConfig(configPath, appState.Config); err != nil {
@@ -351,11 +690,782 @@ func addCommands() {
 				return
 			}
 
-			fmt.Printf("🎯 Weekly study goal updated to: %d credits\n", newGoal)
+			if goalWeekFlag != "" {
+				if newGoal == 0 {
+					fmt.Printf("🎯 Goal override for week %s removed\n", goalWeekFlag)
+				} else {
+					fmt.Printf("🎯 Goal for week %s set to: %d credits\n", goalWeekFlag, newGoal)
+				}
+				return
+			}
+			currentStart, _ := timeutil.GetWeekBounds(timeutil.Now(appState.Config), appState.Config)
+			goal, breakStart := logic.ScheduledGoals(appState.Config, currentStart)
+			fmt.Printf("🎯 Weekly study goal updated to: %d credits (%d break credits), from this week on\n", goal, breakStart)
 		},
 	}
+	goalCmd.Flags().StringVar(&goalWeekFlag, "week", "", "Override the goal for a single week (YYYY-WW)")
+	goalCmd.Flags().IntVar(&goalBreaksFlag, "breaks", 0, "Set the break credits granted at the start of each week")
+
+	var suggestWeeksFlag int
+	var suggestApplyFlag bool
+	goalSuggestCmd := &cobra.Command{
+		Use:   "suggest",
+		Short: "Recommend a challenging but sustainable goal from recent weeks",
+		Long: `Looks at the last --weeks completed weeks (default 8): study, breaks used and earned,
+and how often the goal was met. It recommends a weekly goal a little above your typical
+study, moved at most 15% at a time, and a break start that covers the breaks you take
+beyond those you earn. Use --apply to adopt it from next week onward.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if suggestWeeksFlag <= 0 {
+				errLog(fmt.Errorf("invalid --weeks value: %d. Please provide a positive number", suggestWeeksFlag))
+				return
+			}
+			suggestion, err := logic.SuggestGoals(&appState, suggestWeeksFlag)
+			if err != nil {
+				errLog(err)
+				return
+			}
+
+			fmt.Println(cli.FormatHeader(fmt.Sprintf("🎯 Goal suggestion (last %d weeks)", suggestion.Weeks)))
+			fmt.Printf("Weekly goal ▸ %d (now %d)\n", suggestion.WeeklyGoal, suggestion.CurrentGoal)
+			fmt.Printf("Break start ▸ %d (now %d)\n", suggestion.BreakStart, suggestion.CurrentBreaks)
+			fmt.Println("\nWhy:")
+			for _, reason := range suggestion.Reasons {
+				fmt.Printf("  • %s\n", reason)
+			}
+
+			if !suggestApplyFlag {
+				fmt.Println("\nRun 'grain goal suggest --apply' to adopt it from next week.")
+				return
+			}
+			from := logic.ApplySuggestion(&appState.Config, suggestion)
+			if err := config.SaveConfig(configPath, appState.Config); err != nil {
+				errLog(fmt.Errorf("failed to save updated config file: %w", err))
+				return
+			}
+			fmt.Printf("\n🎯 Applied from week %s: %d credits (%d break credits)\n", from, suggestion.WeeklyGoal, suggestion.BreakStart)
+		},
+	}
+	goalSuggestCmd.Flags().IntVar(&suggestWeeksFlag, "weeks", 8, "Number of completed weeks to learn from")
+	goalSuggestCmd.Flags().BoolVar(&suggestApplyFlag, "apply", false, "Adopt the suggestion from next week onward")
+	goalCmd.AddCommand(goalSuggestCmd)
 	rootCmd.AddCommand(goalCmd)
 
+	// --- Add Simulation Command ---
+	var simGoalFlag, simBreaksFlag, simWeeksFlag int
+	var simMultiplierFlag float64
+	var simRolloverFlag, simRestDaysFlag, simPatternFlag string
+	simulateCmd := &cobra.Command{
+		Use:   "simulate",
+		Short: "🧪 Replay your history under different rules",
+		Long: `Replays every completed week under alternative settings and compares the goals met,
+streaks, surplus and breaks with what your current settings produced. Nothing is saved.
+
+  grain simulate --goal 80 --multiplier 1.5
+  grain simulate --rollover capped:5 --rest-days saturday,sunday
+  grain simulate --pattern 12/1,10,0,15/2,8,6,0 --weeks 12 --goal 60
+
+--pattern replaces your history with identical synthetic weeks: one study[/breaks] value per
+day, starting on your week start. A new --goal or --breaks applies to every week, replacing
+the goal history. Logs on days that become rest days count as the rest-day policy says.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			flags := cmd.Flags()
+			changes := logic.SimChanges{}
+			described := []string{}
+			if flags.Changed("goal") {
+				if simGoalFlag <= 0 {
+					errLog(fmt.Errorf("invalid --goal value: %d. Please provide a positive number", simGoalFlag))
+					return
+				}
+				changes.WeeklyGoal = &simGoalFlag
+				described = append(described, fmt.Sprintf("goal %d", simGoalFlag))
+			}
+			if flags.Changed("breaks") {
+				if simBreaksFlag < 0 {
+					errLog(fmt.Errorf("invalid --breaks value: %d. Please provide zero or a positive number", simBreaksFlag))
+					return
+				}
+				changes.BreakStart = &simBreaksFlag
+				described = append(described, fmt.Sprintf("break start %d", simBreaksFlag))
+			}
+			if flags.Changed("multiplier") {
+				if simMultiplierFlag < 0 {
+					errLog(fmt.Errorf("invalid --multiplier value: %g. Please provide zero or a positive number", simMultiplierFlag))
+					return
+				}
+				changes.SurplusMultiplier = &simMultiplierFlag
+				described = append(described, fmt.Sprintf("surplus multiplier %g", simMultiplierFlag))
+			}
+			if flags.Changed("rollover") {
+				rollover, err := logic.ParseRollover(simRolloverFlag)
+				if err == nil {
+					err = config.ValidateRollover(rollover)
+				}
+				if err != nil {
+					errLog(fmt.Errorf("invalid --rollover value: %w", err))
+					return
+				}
+				changes.Rollover = &rollover
+				described = append(described, fmt.Sprintf("rollover %s", simRolloverFlag))
+			}
+			if flags.Changed("rest-days") {
+				changes.RestDays = []string{}
+				if strings.ToLower(simRestDaysFlag) != "none" {
+					for _, name := range strings.Split(simRestDaysFlag, ",") {
+						day, err := timeutil.ParseWeekday(name)
+						if err != nil {
+							errLog(fmt.Errorf("invalid --rest-days value: %w", err))
+							return
+						}
+						changes.RestDays = append(changes.RestDays, strings.ToLower(day.String()))
+					}
+				}
+				described = append(described, fmt.Sprintf("rest days %s", simRestDaysFlag))
+			}
+			if len(described) == 0 && simPatternFlag == "" {
+				errLog(fmt.Errorf("nothing to simulate. Pass a setting to change (e.g. --goal 80) or a --pattern"))
+				return
+			}
+
+			state := &appState
+			source := "weeks of your history"
+			if simPatternFlag != "" {
+				pattern, err := logic.ParsePattern(simPatternFlag)
+				if err != nil {
+					errLog(err)
+					return
+				}
+				if simWeeksFlag <= 0 {
+					errLog(fmt.Errorf("invalid --weeks value: %d. Please provide a positive number", simWeeksFlag))
+					return
+				}
+				state = logic.SyntheticState(appState.Config, pattern, simWeeksFlag)
+				source = fmt.Sprintf("synthetic weeks of %s", simPatternFlag)
+			}
+
+			current := logic.Simulate(state, appState.Config)
+			simulated := logic.Simulate(state, logic.SimulatedConfig(appState.Config, changes))
+			if current.Weeks == 0 {
+				fmt.Println("No completed weeks to replay yet.")
+				return
+			}
+
+			fmt.Println(cli.FormatHeader(fmt.Sprintf("🧪 Simulation: %d %s", current.Weeks, source)))
+			if len(described) > 0 {
+				fmt.Printf("Changes: %s\n", strings.Join(described, ", "))
+			}
+			fmt.Printf("\n%-16s %10s %10s\n", "", "Current", "Simulated")
+			rows := []struct {
+				label              string
+				current, simulated string
+			}{
+				{"Goals met", fmt.Sprintf("%d/%d", current.GoalsMet, current.Weeks), fmt.Sprintf("%d/%d", simulated.GoalsMet, simulated.Weeks)},
+				{"Streak", fmt.Sprint(current.Streak.Length), fmt.Sprint(simulated.Streak.Length)},
+				{"Longest streak", fmt.Sprint(current.Longest.Length), fmt.Sprint(simulated.Longest.Length)},
+				{"Study counted", fmt.Sprint(current.Study), fmt.Sprint(simulated.Study)},
+				{"Surplus", fmt.Sprint(current.Surplus), fmt.Sprint(simulated.Surplus)},
+				{"Breaks granted", fmt.Sprint(current.BreaksGranted), fmt.Sprint(simulated.BreaksGranted)},
+				{"Breaks earned", fmt.Sprint(current.BreaksEarned), fmt.Sprint(simulated.BreaksEarned)},
+				{"Breaks used", fmt.Sprint(current.BreaksUsed), fmt.Sprint(simulated.BreaksUsed)},
+				{"Breaks lost", fmt.Sprint(current.BreaksLost), fmt.Sprint(simulated.BreaksLost)},
+			}
+			for _, row := range rows {
+				fmt.Printf("%-16s %10s %10s\n", row.label, row.current, row.simulated)
+			}
+		},
+	}
+	simulateCmd.Flags().IntVar(&simGoalFlag, "goal", 0, "Weekly study goal to simulate")
+	simulateCmd.Flags().IntVar(&simBreaksFlag, "breaks", 0, "Break credits granted at the start of each week")
+	simulateCmd.Flags().Float64Var(&simMultiplierFlag, "multiplier", 0, "Break credits earned per study credit above the goal")
+	simulateCmd.Flags().StringVar(&simRolloverFlag, "rollover", "", "Rollover policy: none, full, capped:N or decay:PERCENT")
+	simulateCmd.Flags().StringVar(&simRestDaysFlag, "rest-days", "", "Comma-separated rest days (e.g. saturday,sunday) or 'none'")
+	simulateCmd.Flags().StringVar(&simPatternFlag, "pattern", "", "Synthetic week instead of your history: 7 study[/breaks] values")
+	simulateCmd.Flags().IntVar(&simWeeksFlag, "weeks", 8, "Number of synthetic weeks (with --pattern)")
+	rootCmd.AddCommand(simulateCmd)
+
+	// --- Add Target Commands ---
+	targetCmd := &cobra.Command{
+		Use:   "target",
+		Short: "🏁 Track deadline targets like exams",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Println(cli.FormatHeader("🏁 Targets"))
+			if len(appState.Targets) == 0 {
+				fmt.Println("No targets yet. Add one with: grain target add \"JEE mock\" --credits 600 --by 2026-12-20")
+				return
+			}
+			for i, target := range appState.Targets {
+				if i > 0 {
+					fmt.Println()
+				}
+				fmt.Println(cli.FormatTarget(logic.ProgressFor(&appState, target)))
+			}
+		},
+	}
+
+	var targetCreditsFlag int
+	var targetByFlag string
+	var targetTagFlag string
+	targetAddCmd := &cobra.Command{
+		Use:   "add <name>",
+		Short: "Add a target, e.g. grain target add \"JEE mock\" --credits 600 --by 2026-12-20",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			target := data.Target{Name: args[0], Credits: targetCreditsFlag, By: targetByFlag, Tag: targetTagFlag}
+			if err := logic.AddTarget(&appState, target); err != nil {
+				errLog(err)
+				return
+			}
+			if err := data.SaveState(dataPath, &appState); err != nil {
+				errLog(err)
+				return
+			}
+			added := appState.Targets[len(appState.Targets)-1]
+			fmt.Println(cli.FormatTarget(logic.ProgressFor(&appState, added)))
+		},
+	}
+	targetAddCmd.Flags().IntVar(&targetCreditsFlag, "credits", 0, "Study credits to reach")
+	targetAddCmd.Flags().StringVar(&targetByFlag, "by", "", "Deadline (YYYY-MM-DD)")
+	targetAddCmd.Flags().StringVar(&targetTagFlag, "tag", "", "Only count study with this tag")
+	targetAddCmd.MarkFlagRequired("credits")
+	targetAddCmd.MarkFlagRequired("by")
+
+	targetRmCmd := &cobra.Command{
+		Use:     "rm <name>",
+		Aliases: []string{"remove"},
+		Short:   "Remove a target",
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := logic.RemoveTarget(&appState, args[0]); err != nil {
+				errLog(err)
+				return
+			}
+			if err := data.SaveState(dataPath, &appState); err != nil {
+				errLog(err)
+				return
+			}
+			fmt.Printf("🏁 Target '%s' removed.\n", args[0])
+		},
+	}
+
+	targetCmd.AddCommand(targetAddCmd)
+	targetCmd.AddCommand(targetRmCmd)
+	rootCmd.AddCommand(targetCmd)
+
+	// --- Add Insights Commands ---
+	var insightsWeeksFlag int
+	insightsCmd := &cobra.Command{
+		Use:   "insights",
+		Short: "🔬 Learn from patterns in your logs",
+		Long: `Analyzes the timestamp of every entry: study by hour and weekday, median session size,
+breaks per study credit and your most and least productive weekdays. It also compares daily
+study over the last --weeks weeks (default 4) with the weeks before, and says whether the
+change is statistically meaningful (Mann-Whitney U test, p < 0.05).`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if insightsWeeksFlag <= 0 {
+				errLog(fmt.Errorf("invalid --weeks value: %d. Please provide a positive number", insightsWeeksFlag))
+				return
+			}
+			insights := logic.AnalyzeHabits(&appState, insightsWeeksFlag)
+			fmt.Println(cli.FormatHeader("🔬 Insights"))
+			if insights.Entries == 0 {
+				fmt.Println("No study logged yet.")
+				return
+			}
+			fmt.Printf("📚 Study       ▸ %d credits in %d entries, median session %.1f\n", insights.TotalStudy, insights.Entries, insights.MedianSession)
+			fmt.Printf("🍵 Break ratio ▸ %.2f break credits per study credit\n", insights.BreakRatio())
+
+			fmt.Println("\nBy hour")
+			maxHour := 0
+			for _, credits := range insights.ByHour {
+				maxHour = max(maxHour, credits)
+			}
+			for hour, credits := range insights.ByHour {
+				if credits > 0 {
+					fmt.Printf("  %02d:00  %-20s %d\n", hour, cli.FormatBar(float64(credits), float64(maxHour), 20), credits)
+				}
+			}
+
+			fmt.Println("\nBy weekday (working days)")
+			maxAverage := 0.0
+			for _, day := range insights.Weekdays {
+				maxAverage = math.Max(maxAverage, day.Average)
+			}
+			for _, day := range insights.Weekdays {
+				if day.Days > 0 {
+					fmt.Printf("  %-9s  %-20s %.1f/day\n", day.Weekday, cli.FormatBar(day.Average, maxAverage, 20), day.Average)
+				}
+			}
+			if most, least, ok := insights.MostAndLeastProductive(); ok {
+				fmt.Printf("\n🏆 Most productive  ▸ %s (%.1f/day)\n", most.Weekday, most.Average)
+				fmt.Printf("🐢 Least productive ▸ %s (%.1f/day)\n", least.Weekday, least.Average)
+			}
+
+			comparison := insights.Comparison
+			fmt.Printf("\nLast %d weeks vs the %d before\n", comparison.Weeks, comparison.Weeks)
+			if math.IsNaN(comparison.PValue) {
+				fmt.Println("  Not enough history to compare yet (needs 5 working days in each period).")
+				return
+			}
+			change := ""
+			if comparison.Previous > 0 {
+				change = fmt.Sprintf(" (%+.0f%%)", (comparison.Recent-comparison.Previous)/comparison.Previous*100)
+			}
+			fmt.Printf("  %.1f/day vs %.1f/day%s\n", comparison.Recent, comparison.Previous, change)
+			if comparison.Significant() {
+				fmt.Printf("  📈 A meaningful change (p = %.3f)\n", comparison.PValue)
+			} else {
+				fmt.Printf("  〰️  Within normal variation (p = %.2f)\n", comparison.PValue)
+			}
+		},
+	}
+	insightsCmd.Flags().IntVar(&insightsWeeksFlag, "weeks", 4, "Length in weeks of the periods compared")
+
+	insightsMoodCmd := &cobra.Command{
+		Use:   "mood",
+		Short: "Relate focus and energy ratings to when and how you study",
+		Long: `Averages the --focus and --energy ratings of your study entries by time of day,
+weekday, session size and whether you took a break in the 90 minutes before,
+so you can see when your focus is actually good. Rate entries with e.g. 'grain s 2 --focus 4'.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			insights := logic.AnalyzeMood(&appState)
+			fmt.Println(cli.FormatHeader("🔬 Focus & Energy"))
+			if insights.Rated == 0 {
+				fmt.Println("No rated entries yet. Add ratings when logging, e.g. grain s 2 --focus 4 --energy 3")
+				return
+			}
+			fmt.Printf("Rated entries: %d\n", insights.Rated)
+
+			sections := []struct {
+				title  string
+				groups []logic.RatingGroup
+			}{
+				{"Time of day", insights.TimeOfDay},
+				{"Weekday", insights.Weekdays},
+				{"Session size", insights.SessionSize},
+				{"Breaks beforehand", insights.AfterBreak},
+			}
+			for _, section := range sections {
+				fmt.Printf("\n%s\n", section.title)
+				for _, group := range section.groups {
+					if group.Entries > 0 {
+						fmt.Printf("  %s\n", cli.FormatRatingGroup(group))
+					}
+				}
+				if best, ok := logic.BestFocus(section.groups); ok {
+					fmt.Printf("  ⭐ Best focus: %s (%.1f)\n", best.Label, best.FocusAverage)
+				}
+			}
+
+			if r := insights.SizeFocusCorrelation; !math.IsNaN(r) {
+				trend := "barely changes with"
+				switch {
+				case r >= 0.3:
+					trend = "rises with"
+				case r <= -0.3:
+					trend = "drops with"
+				}
+				fmt.Printf("\n📐 Focus %s session size (r = %.2f)\n", trend, r)
+			}
+		},
+	}
+
+	insightsCmd.AddCommand(insightsMoodCmd)
+	rootCmd.AddCommand(insightsCmd)
+
+	// --- Add Reflection Command ---
+	var reflectShowFlag bool
+	reflectCmd := &cobra.Command{
+		Use:   "reflect [YYYY-WW]",
+		Short: "✍️  Write this week's reflection in $EDITOR",
+		Long: `Opens a Markdown reflection for the current week in $EDITOR, starting from a template
+with the week's numbers (what worked, what to adjust). Saving an empty file deletes it.
+Pass a week ID to reflect on another week, and --show to print a reflection,
+e.g. 'grain reflect --show 2026-40'.`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			weekStart, _ := timeutil.GetWeekBounds(timeutil.Now(appState.Config), appState.Config)
+			if len(args) == 1 {
+				var err error
+				if weekStart, err = timeutil.WeekStartFromID(args[0], appState.Config); err != nil {
+					errLog(err)
+					return
+				}
+			}
+
+			if reflectShowFlag {
+				weekID := timeutil.GetWeekIDForDate(weekStart, appState.Config)
+				reflection, found := appState.Reflections[weekID]
+				if !found {
+					fmt.Printf("No reflection for week %s yet. Write one with: grain reflect %s\n", weekID, weekID)
+					return
+				}
+				fmt.Println(cli.FormatHeader(fmt.Sprintf("✍️  Reflection %s", weekID)))
+				fmt.Println(reflection.Text)
+				fmt.Printf("\n(last edited %s)\n", reflection.Updated.In(timeutil.Location(appState.Config)).Format("2006-01-02 15:04"))
+				return
+			}
+
+			summary := logic.SummarizeWeek(&appState, weekStart)
+			template := logic.ReflectionTemplate(summary)
+			content := template
+			if reflection, found := appState.Reflections[summary.ID]; found {
+				content = reflection.Text + "\n"
+			}
+
+			editor, err := findEditor()
+			if err != nil {
+				errLog(err)
+				return
+			}
+			file, err := os.CreateTemp("", fmt.Sprintf("grain-reflect-%s-*.md", summary.ID))
+			if err != nil {
+				errLog(fmt.Errorf("could not create reflection file: %w", err))
+				return
+			}
+			defer os.Remove(file.Name())
+			_, err = file.WriteString(content)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				errLog(fmt.Errorf("could not write reflection file: %w", err))
+				return
+			}
+
+			if err := runEditor(editor, file.Name()); err != nil {
+				errLog(err)
+				return
+			}
+			edited, err := os.ReadFile(file.Name())
+			if err != nil {
+				errLog(fmt.Errorf("could not read reflection file: %w", err))
+				return
+			}
+
+			if !logic.SaveReflection(&appState, summary.ID, string(edited), template, time.Now()) {
+				fmt.Println("Reflection unchanged.")
+				return
+			}
+			if err := data.SaveState(dataPath, &appState); err != nil {
+				errLog(err)
+				return
+			}
+			if logic.HasReflection(&appState, summary.ID) {
+				fmt.Printf("✍️  Reflection for week %s saved.\n", summary.ID)
+			} else {
+				fmt.Printf("✍️  Reflection for week %s deleted.\n", summary.ID)
+			}
+		},
+	}
+	reflectCmd.Flags().BoolVar(&reflectShowFlag, "show", false, "Print the reflection instead of editing it")
+	rootCmd.AddCommand(reflectCmd)
+
+	// --- Add Plan Commands ---
+	planCmd := &cobra.Command{
+		Use:   "plan",
+		Short: "📋 Schedule study blocks ahead of time",
+		Long: `Plans study blocks by day and time, e.g. 'grain plan add tomorrow 09:00-11:00 3 --tag physics'.
+Use 'grain plan review' to compare the plan with what you actually logged, and
+'grain plan export plan.ics' to put the blocks in your calendar. Without a subcommand,
+lists the blocks planned from today onward.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Println(cli.FormatHeader("📋 Plan"))
+			upcoming := logic.UpcomingPlan(&appState)
+			if len(upcoming) == 0 {
+				fmt.Println("Nothing planned. Add a block with: grain plan add tomorrow 09:00-11:00 3")
+				return
+			}
+			lastDate := ""
+			for _, block := range upcoming {
+				if block.Date != lastDate {
+					date, _ := timeutil.ParseDate(block.Date, appState.Config)
+					fmt.Printf("%s\n", date.Format("Mon Jan 2"))
+					lastDate = block.Date
+				}
+				fmt.Printf("  %s\n", cli.FormatPlanBlock(block))
+			}
+		},
+	}
+
+	var planTagFlag string
+	planAddCmd := &cobra.Command{
+		Use:   "add <day> <HH:MM-HH:MM> <credits>",
+		Short: "Plan a study block, e.g. grain plan add monday 18:00-20:00 2",
+		Long: `Plans a study block. The day can be 'today', 'tomorrow', a weekday (its next
+occurrence) or YYYY-MM-DD. Blocks on the same day may not overlap.`,
+		Args: cobra.ExactArgs(3),
+		Run: func(cmd *cobra.Command, args []string) {
+			day, err := timeutil.ParseDay(args[0], appState.Config)
+			if err != nil {
+				errLog(err)
+				return
+			}
+			start, end, found := strings.Cut(args[1], "-")
+			if !found {
+				errLog(fmt.Errorf("invalid time range: '%s'. Use HH:MM-HH:MM", args[1]))
+				return
+			}
+			credits, err := strconv.Atoi(args[2])
+			if err != nil || credits <= 0 {
+				errLog(fmt.Errorf("invalid amount: '%s'. Please provide a positive number", args[2]))
+				return
+			}
+
+			block := data.PlanBlock{Date: day.Format(data.DateFormat), Start: start, End: end, Credits: credits, Tag: planTagFlag}
+			block, err = logic.AddPlanBlock(&appState, block)
+			if err != nil {
+				errLog(err)
+				return
+			}
+			if err := data.SaveState(dataPath, &appState); err != nil {
+				errLog(err)
+				return
+			}
+			fmt.Printf("📋 Planned for %s: %s\n", day.Format("Mon Jan 2"), cli.FormatPlanBlock(block))
+		},
+	}
+	planAddCmd.Flags().StringVar(&planTagFlag, "tag", "", "Subject for the block (e.g. physics)")
+
+	planRmCmd := &cobra.Command{
+		Use:     "rm <day> <HH:MM>",
+		Aliases: []string{"remove"},
+		Short:   "Remove the block starting at a given time",
+		Args:    cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			day, err := timeutil.ParseDay(args[0], appState.Config)
+			if err != nil {
+				errLog(err)
+				return
+			}
+			removed, err := logic.RemovePlanBlock(&appState, day.Format(data.DateFormat), args[1])
+			if err != nil {
+				errLog(err)
+				return
+			}
+			if err := data.SaveState(dataPath, &appState); err != nil {
+				errLog(err)
+				return
+			}
+			fmt.Printf("📋 Removed %s on %s.\n", cli.FormatPlanBlock(removed), day.Format("Mon Jan 2"))
+		},
+	}
+
+	var planWeekFlag string
+	planReviewCmd := &cobra.Command{
+		Use:   "review",
+		Short: "Compare the plan with what you logged",
+		Long: `Shows planned against logged study credits per day and for the week, with
+adherence (share of planned credits studied), slippage (planned credits not studied) and
+how much was studied on time, during a planned block or within 30 minutes after it.
+Defaults to the current week; use --week YYYY-WW for another.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			weekStart, _ := timeutil.GetWeekBounds(timeutil.Now(appState.Config), appState.Config)
+			if planWeekFlag != "" {
+				var err error
+				if weekStart, err = timeutil.WeekStartFromID(planWeekFlag, appState.Config); err != nil {
+					errLog(err)
+					return
+				}
+			}
+			review := logic.ReviewPlan(&appState, weekStart)
+
+			fmt.Println(cli.FormatHeader(fmt.Sprintf("📋 Plan review %s", review.WeekID)))
+			if len(review.Days) == 0 {
+				fmt.Println("Nothing planned or logged this week.")
+				return
+			}
+			for _, day := range review.Days {
+				date, _ := timeutil.ParseDate(day.Date, appState.Config)
+				status := ""
+				switch {
+				case day.Future:
+					status = "⏳ ahead"
+				case day.Planned == 0:
+					status = "➕ unplanned"
+				case day.Slipped() > 0:
+					status = fmt.Sprintf("↘️  slipped %d", day.Slipped())
+				default:
+					status = "✅ done"
+				}
+				fmt.Printf("%-10s  planned %2d · logged %2d · on time %2d  %s\n", date.Format("Mon Jan 2"), day.Planned, day.Actual, day.OnTime, status)
+			}
+
+			fmt.Println()
+			if review.Planned > 0 {
+				fmt.Printf("Adherence ▸ %d%% (%d of %d planned credits)\n", review.Adherence(), review.Done, review.Planned)
+				fmt.Printf("On time   ▸ %d credits\n", review.OnTime)
+				fmt.Printf("Slipped   ▸ %d credits\n", review.Slipped)
+			}
+			if review.Extra > 0 {
+				fmt.Printf("Extra     ▸ %d credits beyond the plan\n", review.Extra)
+			}
+			if review.Ahead > 0 {
+				fmt.Printf("Ahead     ▸ %d credits still planned this week\n", review.Ahead)
+			}
+		},
+	}
+	planReviewCmd.Flags().StringVar(&planWeekFlag, "week", "", "Week to review (YYYY-WW)")
+
+	planExportCmd := &cobra.Command{
+		Use:   "export <file.ics>",
+		Short: "Export planned blocks to an iCalendar (.ics) file",
+		Long: `Writes every planned block from today onward to an .ics file you can import or
+subscribe to in a calendar app. Re-exporting updates the same events rather than duplicating them.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			events := logic.PlanEvents(&appState, logic.UpcomingPlan(&appState))
+			file, err := os.Create(args[0])
+			if err != nil {
+				errLog(fmt.Errorf("could not create calendar file '%s': %w", args[0], err))
+				return
+			}
+			if err := ics.Write(file, events, time.Now()); err != nil {
+				file.Close()
+				errLog(err)
+				return
+			}
+			if err := file.Close(); err != nil {
+				errLog(fmt.Errorf("could not write calendar file '%s': %w", args[0], err))
+				return
+			}
+			fmt.Printf("📅 Exported %d planned blocks to %s\n", len(events), args[0])
+		},
+	}
+
+	planCmd.AddCommand(planAddCmd)
+	planCmd.AddCommand(planRmCmd)
+	planCmd.AddCommand(planReviewCmd)
+	planCmd.AddCommand(planExportCmd)
+	rootCmd.AddCommand(planCmd)
+
+	// --- Add Streak Freeze Command ---
+	var freezeWeekFlag string
+	freezeCmd := &cobra.Command{
+		Use:   "freeze",
+		Short: "🧊 Protect your streak from a missed week",
+		Long: `Spends a streak freeze token on a missed week, e.g. 'grain freeze --week 2026-41',
+so it doesn't reset your streak. You get freezes_per_month tokens per month (set in config);
+with auto_freeze on, one is spent automatically when last week's goal was missed.
+Without --week, lists every freeze used.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if freezeWeekFlag != "" {
+				if err := logic.UseFreeze(&appState, freezeWeekFlag, false); err != nil {
+					errLog(err)
+					return
+				}
+				if err := data.SaveState(dataPath, &appState); err != nil {
+					errLog(err)
+					return
+				}
+				fmt.Printf("🧊 Week %s frozen. Streak ▸ %d weeks\n", freezeWeekFlag, appState.Streak)
+				return
+			}
+
+			fmt.Println(cli.FormatHeader("🧊 Streak Freezes"))
+			fmt.Printf("Tokens left this month: %d of %d\n", logic.FreezesLeft(&appState, timeutil.Now(appState.Config)), appState.Config.FreezesPerMonth)
+			if len(appState.Freezes) == 0 {
+				fmt.Println("No freezes used yet.")
+				return
+			}
+			for _, freeze := range appState.Freezes {
+				how := "manual"
+				if freeze.Auto {
+					how = "auto"
+				}
+				fmt.Printf("%s  used %s (%s)\n", freeze.Week, freeze.UsedAt.Format("2006-01-02 15:04"), how)
+			}
+		},
+	}
+	freezeCmd.Flags().StringVar(&freezeWeekFlag, "week", "", "Missed week to freeze (YYYY-WW)")
+	rootCmd.AddCommand(freezeCmd)
+
+	// --- Add Days Off Command ---
+	var offReasonFlag string
+	var offRemoveFlag bool
+	var offImportFlag string
+	offCmd := &cobra.Command{
+		Use:   "off [YYYY-MM-DD[..YYYY-MM-DD]]",
+		Short: "🏖️  Record holidays and vacation days",
+		Long: `Records days off, e.g. 'grain off 2026-12-24..2026-12-31 --reason holiday'.
+Days off scale the weekly goal down by the working days left, and weeks taken
+entirely off don't break your streak. Use --import to read all-day events from
+an .ics calendar; re-importing the same file is safe. Without arguments, lists your days off.`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if offImportFlag != "" {
+				file, err := os.Open(offImportFlag)
+				if err != nil {
+					errLog(fmt.Errorf("could not open calendar file '%s': %w", offImportFlag, err))
+					return
+				}
+				events, err := ics.Parse(file, timeutil.Location(appState.Config))
+				file.Close()
+				if err != nil {
+					errLog(fmt.Errorf("could not parse calendar file '%s': %w", offImportFlag, err))
+					return
+				}
+				added, skipped := logic.ImportDaysOff(&appState, events)
+				if err := data.SaveState(dataPath, &appState); err != nil {
+					errLog(err)
+					return
+				}
+				fmt.Printf("📅 Imported %d days off from %d events", added, len(events)-skipped)
+				if skipped > 0 {
+					fmt.Printf(" (%d timed, UID-less or unsupported recurring events skipped)", skipped)
+				}
+				fmt.Println()
+				return
+			}
+
+			if len(args) == 0 {
+				fmt.Println(cli.FormatHeader("🏖️  Days off"))
+				if len(appState.DaysOff) == 0 {
+					fmt.Println("No days off recorded.")
+					return
+				}
+				for _, off := range appState.DaysOff {
+					fmt.Printf("%s  %s\n", off.Date, off.Reason)
+				}
+				return
+			}
+
+			from, to, err := timeutil.ParseDateRange(args[0], appState.Config)
+			if err != nil {
+				errLog(err)
+				return
+			}
+
+			if offRemoveFlag {
+				removed := logic.RemoveDaysOff(&appState, from, to)
+				if err := data.SaveState(dataPath, &appState); err != nil {
+					errLog(err)
+					return
+				}
+				fmt.Printf("🏖️  Removed %d days off.\n", removed)
+				return
+			}
+
+			count := logic.AddDaysOff(&appState, from, to, offReasonFlag)
+			if err := data.SaveState(dataPath, &appState); err != nil {
+				errLog(err)
+				return
+			}
+			fmt.Printf("🏖️  %d days off recorded (%s). Rest well.\n", count, offReasonFlag)
+		},
+	}
+	offCmd.Flags().StringVar(&offReasonFlag, "reason", "day off", "Why you're taking the time off (e.g. holiday, vacation)")
+	offCmd.Flags().BoolVar(&offRemoveFlag, "remove", false, "Remove the given days off instead of adding them")
+	offCmd.Flags().StringVar(&offImportFlag, "import", "", "Import all-day events from an iCalendar (.ics) file as days off")
+	rootCmd.AddCommand(offCmd)
+
 	// --- Add Action Commands ---
 	undoCmd := &cobra.Command{
 		Use:   "undo",
@@ -381,29 +1491,15 @@ func addCommands() {
 		Short: "⚙️  Opens config file in your default editor ($EDITOR)",
 		Args:  cobra.NoArgs,
 		Run: func(cmd *cobra.Command, args []string) {
-			editor := os.Getenv("EDITOR")
-			if editor == "" {
-				// Simple default lookup
-				if _, err := exec.LookPath("vim"); err == nil {
-					editor = "vim"
-				} else if _, err := exec.LookPath("nano"); err == nil {
-					editor = "nano"
-				} else if _, err := exec.LookPath("code"); err == nil { // Common VS Code
-					editor = "code"
-				} else {
-					errLog(fmt.Errorf("EDITOR environment variable not set and common editors (vim, nano, code) not found.\nPlease edit manually: %s", configPath))
-					return
-				}
+			editor, err := findEditor()
+			if err != nil {
+				errLog(fmt.Errorf("%w\nPlease edit manually: %s", err, configPath))
+				return
 			}
 
 			fmt.Printf("Attempting to open %s with %s...\n", configPath, editor)
-			editorCmd := exec.Command(editor, configPath)
-			editorCmd.Stdin = os.Stdin
-			editorCmd.Stdout = os.Stdout
-			editorCmd.Stderr = os.Stderr
-
-			if err := editorCmd.Run(); err != nil {
-				errLog(fmt.Errorf("failed to open editor '%s': %w\nCheck if '%s' is in your PATH.", editor, err, editor))
+			if err := runEditor(editor, configPath); err != nil {
+				errLog(err)
 				return
 			}
 			fmt.Println("Editor closed. Configuration changes will be applied the next time you run grain.")
@@ -437,7 +1533,14 @@ func addCommands() {
 		Short: "🗃️ Saves a timestamped backup of all data",
 		Args:  cobra.NoArgs,
 		Run: func(cmd *cobra.Command, args []string) {
-			backupFile, err := data.BackupData(dataPath, backupDir)
+			var backupFile string
+			var err error
+			if appState.ReadOnly {
+				// There's no intact data.json to copy, so back up what was salvaged
+				backupFile, err = data.BackupState(&appState, backupDir)
+			} else {
+				backupFile, err = data.BackupData(dataPath, backupDir)
+			}
 			if err != nil {
 				errLog(err)
 				return
@@ -452,20 +1555,31 @@ func addCommands() {
 	}
 
 	restoreCmd := &cobra.Command{
-		Use:   "restore <backup_file_name>",
+		Use:   "restore [backup_file_name]",
 		Short: "♻️  Loads state from a backup file in ~/.grain/backups/",
 		Long: `Restores the application state from a specified backup file. 
 The backup file name should exist within the ~/.grain/backups/ directory. 
+Without a name, the newest valid backup is used.
 This action will overwrite your current data.json file.`,
-		Args: cobra.ExactArgs(1),
+		Args: cobra.MaximumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			backupFileName := args[0]
-			// Ensure the provided name doesn't contain path separators
-			if filepath.Base(backupFileName) != backupFileName {
-				errLog(fmt.Errorf("invalid backup file name: '%s'. Please provide only the filename, not a path.", backupFileName))
-				return
+			var backupFilePath string
+			if len(args) == 0 {
+				latest, err := data.LatestValidBackup(backupDir)
+				if err != nil {
+					errLog(err)
+					return
+				}
+				backupFilePath = latest
+			} else {
+				// Ensure the provided name doesn't contain path separators
+				if filepath.Base(args[0]) != args[0] {
+					errLog(fmt.Errorf("invalid backup file name: '%s'. Please provide only the filename, not a path.", args[0]))
+					return
+				}
+				backupFilePath = filepath.Join(backupDir, args[0])
 			}
-			backupFilePath := filepath.Join(backupDir, backupFileName)
+			backupFileName := filepath.Base(backupFilePath)
 
 			// Use a simple 'yes' confirmation for restore
 			if cli.PromptConfirmation(fmt.Sprintf("⚠️ This will overwrite current data with the contents of '%s'.\nType \"yes\" to confirm:", backupFileName)) {
@@ -489,8 +1603,60 @@ This action will overwrite your current data.json file.`,
 		},
 	}
 
+	var doctorFixFlag bool
+	doctorCmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "🩺 Checks data.json for inconsistencies and optionally repairs them",
+		Long: `Checks data.json for unsorted, duplicate or empty days, malformed dates, invalid entries,
+entries on refused rest days, undo steps pointing at missing entries, and stored surplus
+values that don't match a fresh recomputation. With --fix, a backup is saved first and then
+everything found is repaired.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if appState.ReadOnly {
+				errLog(fmt.Errorf("data.json is corrupted and was moved aside; restore a backup with 'grain restore' first"))
+				return
+			}
+			// appState is the file as stored: loadConfigAndState skips its recalculations for doctor
+			issues := logic.Diagnose(&appState)
+			if len(issues) == 0 {
+				fmt.Println("🩺 No problems found. Your data is healthy.")
+				return
+			}
+
+			restEntries := false
+			fmt.Printf("🩺 Found %d problems:\n", len(issues))
+			for _, issue := range issues {
+				fmt.Printf("  ⚠️  %-8s %s\n", issue.Check, issue.Detail)
+				restEntries = restEntries || issue.Check == logic.CheckRest
+			}
+			if !doctorFixFlag {
+				fmt.Println("Run 'grain doctor --fix' to repair them (a backup is saved first).")
+				if restEntries {
+					fmt.Println("--fix removes the rest-day entries. To count them instead, set rest_day_policy to previous, next or bonus first.")
+				}
+				return
+			}
+
+			backupFile, err := data.BackupData(dataPath, backupDir)
+			if err != nil {
+				errLog(fmt.Errorf("not repairing without a backup: %w", err))
+				return
+			}
+			logic.Repair(&appState)
+			logic.RecalculateOverallStats(&appState)
+			if err := data.SaveState(dataPath, &appState); err != nil {
+				errLog(err)
+				return
+			}
+			fmt.Printf("🩹 Repaired. The previous data was backed up to %s\n", filepath.Base(backupFile))
+		},
+	}
+	doctorCmd.Flags().BoolVar(&doctorFixFlag, "fix", false, "Repair the problems found, after saving a backup")
+
 	rootCmd.AddCommand(undoCmd)
 	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(doctorCmd)
 	rootCmd.AddCommand(resetCmd)
 	rootCmd.AddCommand(backupCmd)
 	rootCmd.AddCommand(restoreCmd)
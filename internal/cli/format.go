@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"grain/internal/data"
+	"grain/internal/logic"
 )
 
 const separator = "────────────────────────────"
@@ -23,7 +24,134 @@ func FormatLogEntry(log data.Log) string {
 	if log.Type == data.LogTypeBreak {
 		sign = "-"
 	}
-	return fmt.Sprintf("[%s] %s%d %s", log.Timestamp.Format("15:04"), sign, log.Amount, log.Type)
+	entry := fmt.Sprintf("[%s] %s%d %s", log.Timestamp.Format("15:04"), sign, log.Amount, log.Type)
+	if log.Tag != "" {
+		entry += fmt.Sprintf(" #%s", log.Tag)
+	}
+	if log.Focus > 0 {
+		entry += fmt.Sprintf(" 🎯%d", log.Focus)
+	}
+	if log.Energy > 0 {
+		entry += fmt.Sprintf(" ⚡%d", log.Energy)
+	}
+	return entry
+}
+
+// FormatWeekRow formats one week as a single line for history views.
+func FormatWeekRow(weekID string, start time.Time, study, goal int, status string, daysOff int, reflected bool) string {
+	row := fmt.Sprintf("%s  %-6s  🧠 %3d / %-3d  %s", weekID, start.Format("Jan 2"), study, goal, status)
+	if daysOff > 0 {
+		row += fmt.Sprintf("  🏖️ %d off", daysOff)
+	}
+	if reflected {
+		row += "  ✍️"
+	}
+	return row
+}
+
+// FormatStreakRecord formats a streak record with its dates, e.g. "9 weeks (2026-01-05 → 2026-03-08)".
+func FormatStreakRecord(record data.StreakRecord, unit string) string {
+	if record.Length == 0 {
+		return fmt.Sprintf("0 %s", unit)
+	}
+	return fmt.Sprintf("%d %s (%s → %s)", record.Length, unit, record.Start, record.End)
+}
+
+// FormatTarget formats a target's progress, countdown and required pace.
+func FormatTarget(progress logic.TargetProgress) string {
+	target := progress.Target
+	title := fmt.Sprintf("🎯 %s", target.Name)
+	if target.Tag != "" {
+		title += fmt.Sprintf(" #%s", target.Tag)
+	}
+
+	lines := []string{
+		title,
+		fmt.Sprintf("   %d / %d credits · due %s · %d days left (%d working)", progress.Done, target.Credits, target.By, progress.DaysLeft, progress.WorkingDaysLeft),
+	}
+	switch {
+	case progress.Remaining == 0:
+		lines = append(lines, "   ✅ Reached. Well done.")
+	case !progress.Reachable:
+		lines = append(lines, fmt.Sprintf("   ⚠️  Needs %d/week, but your weekly goals only add up to %d more credits before the deadline", progress.RequiredWeekly, progress.Capacity))
+	default:
+		lines = append(lines, fmt.Sprintf("   📐 Needs %d/week to finish on time", progress.RequiredWeekly))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// FormatPlanBlock formats a planned block, e.g. "09:00-11:00  3 credits #physics".
+func FormatPlanBlock(block data.PlanBlock) string {
+	line := fmt.Sprintf("%s-%s  %d credits", block.Start, block.End, block.Credits)
+	if block.Tag != "" {
+		line += fmt.Sprintf(" #%s", block.Tag)
+	}
+	return line
+}
+
+// FormatRatingGroup formats a group's average ratings as a table row, e.g.
+// "Morning (5-12)      🎯 4.2  ⚡ 3.8  (12 entries)".
+func FormatRatingGroup(group logic.RatingGroup) string {
+	average := func(value float64, count int) string {
+		if count == 0 {
+			return "  -"
+		}
+		return fmt.Sprintf("%.1f", value)
+	}
+	row := fmt.Sprintf("%-18s  🎯 %s  ⚡ %s  (%d entries)", group.Label, average(group.FocusAverage, group.FocusCount), average(group.EnergyAverage, group.EnergyCount), group.Entries)
+	if !group.Enough() {
+		row += " · too few to compare"
+	}
+	return row
+}
+
+// heatLevels shade heatmap days from a little study to the busiest day shown.
+var heatLevels = []string{"░", "▒", "▓", "█"}
+
+// FormatHeatmap draws the heatmap with one row per weekday and one column per week, oldest on the left.
+func FormatHeatmap(weeks [][]logic.HeatDay) string {
+	busiest := 0
+	for _, week := range weeks {
+		for _, day := range week {
+			busiest = max(busiest, day.Study)
+		}
+	}
+
+	lines := []string{}
+	for row := 0; len(weeks) > 0 && row < 7; row++ {
+		cells := []string{weeks[0][row].Date.Format("Mon")}
+		for _, week := range weeks {
+			day := week[row]
+			cell := "·"
+			switch {
+			case day.Future:
+				cell = " "
+			case day.Study > 0:
+				cell = heatLevels[(day.Study*len(heatLevels)-1)/busiest]
+			case day.Off:
+				cell = "○"
+			case day.Rest:
+				cell = "-"
+			}
+			cells = append(cells, cell)
+		}
+		lines = append(lines, strings.Join(cells, " "))
+	}
+	if len(weeks) > 0 {
+		first, last := weeks[0][0].Date, weeks[len(weeks)-1][0].Date
+		lines = append(lines, fmt.Sprintf("Weeks of %s → %s", first.Format("Jan 2"), last.Format("Jan 2")))
+	}
+	lines = append(lines, fmt.Sprintf("· no study  %s more study  ○ day off  - rest day", strings.Join(heatLevels, "")))
+	return strings.Join(lines, "\n")
+}
+
+// FormatBar draws value as a bar of up to width blocks, scaled against maxValue.
+func FormatBar(value, maxValue float64, width int) string {
+	if maxValue <= 0 || value <= 0 {
+		return ""
+	}
+	blocks := int(value / maxValue * float64(width))
+	return strings.Repeat("█", max(blocks, 1))
 }
 
 // FormatDuration formats a duration in a human-readable way (e.g., 1h 30m).
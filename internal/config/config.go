@@ -6,15 +6,22 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"time"
 
 	"bufio"
 	"grain/internal/cli"
 	"grain/internal/data"
+	"grain/internal/timeutil"
 )
 
 const (
 	defaultWeeklyGoal = 90
 	defaultBreakStart = 12
+	defaultWeekStart  = "monday"
+	defaultRestDay    = "sunday"
+	defaultDayStart   = "00:00"
+	defaultDebtShare  = 100
+	defaultDailyMin   = 1
 	configFileName    = "config.json"
 	dataFileName      = "data.json"
 	backupDirName     = "backups"
@@ -91,6 +98,15 @@ func LoadConfig(configPath string) (data.Config, error) {
 			}
 		}
 
+		cfg.WeekStart = defaultWeekStart
+		cfg.RestDays = []string{defaultRestDay}
+		cfg.RestDayPolicy = data.RestDayRefuse
+		cfg.DailyMinimum = defaultDailyMin
+		cfg.DayStartsAt = defaultDayStart
+		cfg.BreakRules = &data.BreakRules{SurplusMultiplier: data.DefaultSurplusMultiplier}
+		cfg.Rollover.Mode = data.RolloverNone
+		cfg.Debt.Percent = defaultDebtShare
+
 		if err := SaveConfig(configPath, cfg); err != nil {
 			return cfg, fmt.Errorf("❌ failed to save initial config: %w", err)
 		}
@@ -115,10 +131,111 @@ func LoadConfig(configPath string) (data.Config, error) {
 	if cfg.BreakStart < 0 {
 		cfg.BreakStart = defaultBreakStart
 	}
+	if cfg.WeekStart == "" {
+		cfg.WeekStart = defaultWeekStart
+	}
+	if _, err := timeutil.ParseWeekday(cfg.WeekStart); err != nil {
+		return cfg, fmt.Errorf("❌ invalid week_start in config file '%s': %w", configPath, err)
+	}
+	// A missing rest_days key keeps the Sunday default; an explicit empty list means no rest days
+	if cfg.RestDays == nil {
+		cfg.RestDays = []string{defaultRestDay}
+	}
+	for _, name := range cfg.RestDays {
+		if _, err := timeutil.ParseWeekday(name); err != nil {
+			return cfg, fmt.Errorf("❌ invalid rest_days entry in config file '%s': %w", configPath, err)
+		}
+	}
+	switch cfg.RestDayPolicy {
+	case "":
+		cfg.RestDayPolicy = data.RestDayRefuse
+	case data.RestDayRefuse, data.RestDayPrevious, data.RestDayNext, data.RestDayBonus:
+	default:
+		return cfg, fmt.Errorf("❌ invalid rest_day_policy '%s' in config file '%s'. Use refuse, previous, next or bonus", cfg.RestDayPolicy, configPath)
+	}
+	if cfg.FreezesPerMonth < 0 {
+		return cfg, fmt.Errorf("❌ invalid freezes_per_month in config file '%s': must not be negative", configPath)
+	}
+	if cfg.DailyMinimum <= 0 {
+		cfg.DailyMinimum = defaultDailyMin
+	}
+	if cfg.DayStartsAt == "" {
+		cfg.DayStartsAt = defaultDayStart
+	}
+	if _, _, err := timeutil.ParseClock(cfg.DayStartsAt); err != nil {
+		return cfg, fmt.Errorf("❌ invalid day_starts_at in config file '%s': %w", configPath, err)
+	}
+	if cfg.BreakRules == nil {
+		cfg.BreakRules = &data.BreakRules{SurplusMultiplier: data.DefaultSurplusMultiplier}
+	}
+	if err := validateBreakRules(*cfg.BreakRules); err != nil {
+		return cfg, fmt.Errorf("❌ invalid break_rules in config file '%s': %w", configPath, err)
+	}
+	if cfg.Rollover.Mode == "" {
+		cfg.Rollover.Mode = data.RolloverNone
+	}
+	if err := ValidateRollover(cfg.Rollover); err != nil {
+		return cfg, fmt.Errorf("❌ invalid rollover in config file '%s': %w", configPath, err)
+	}
+	if cfg.Debt.Percent == 0 {
+		cfg.Debt.Percent = defaultDebtShare
+	}
+	if cfg.Debt.Percent < 0 || cfg.Debt.Percent > 100 || cfg.Debt.Cap < 0 {
+		return cfg, fmt.Errorf("❌ invalid debt in config file '%s': percent must be 1-100 and cap must not be negative", configPath)
+	}
+	if cfg.BorrowLimit < 0 {
+		return cfg, fmt.Errorf("❌ invalid borrow_limit in config file '%s': must not be negative", configPath)
+	}
+	for _, change := range cfg.GoalHistory {
+		if change.WeeklyGoal <= 0 || change.BreakStart < 0 {
+			return cfg, fmt.Errorf("❌ invalid goal_history in config file '%s': goals must be positive and break starts non-negative", configPath)
+		}
+		if change.From != "" {
+			if _, err := time.Parse(data.DateFormat, change.From); err != nil {
+				return cfg, fmt.Errorf("❌ invalid goal_history date '%s' in config file '%s'", change.From, configPath)
+			}
+		}
+	}
+	if cfg.Timezone != "" {
+		if _, err := time.LoadLocation(cfg.Timezone); err != nil {
+			return cfg, fmt.Errorf("❌ invalid timezone in config file '%s': %w", configPath, err)
+		}
+	}
 
 	return cfg, nil
 }
 
+// validateBreakRules rejects break rules that would make the engine give nonsensical results.
+func validateBreakRules(rules data.BreakRules) error {
+	if rules.SurplusMultiplier < 0 || rules.EarnEvery < 0 || rules.EarnBreaks < 0 || rules.MaxPerWeek < 0 {
+		return fmt.Errorf("values must not be negative")
+	}
+	for _, tier := range rules.Tiers {
+		if tier.Study <= 0 || tier.Bonus < 0 {
+			return fmt.Errorf("each tier needs a positive study threshold and a non-negative bonus")
+		}
+	}
+	return nil
+}
+
+// ValidateRollover checks the rollover mode and its parameters.
+func ValidateRollover(rollover data.Rollover) error {
+	switch rollover.Mode {
+	case data.RolloverNone, data.RolloverFull:
+	case data.RolloverCapped:
+		if rollover.Cap < 0 {
+			return fmt.Errorf("cap must not be negative")
+		}
+	case data.RolloverDecay:
+		if rollover.DecayPercent < 0 || rollover.DecayPercent > 100 {
+			return fmt.Errorf("decay_percent must be between 0 and 100")
+		}
+	default:
+		return fmt.Errorf("unknown mode '%s'. Use none, full, capped or decay", rollover.Mode)
+	}
+	return nil
+}
+
 // SaveConfig saves the configuration to config.json.
 func SaveConfig(configPath string, cfg data.Config) error {
 	bytes, err := json.MarshalIndent(cfg, "", "  ")
@@ -0,0 +1,198 @@
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// fileTimeFormat timestamps backups and quarantined data files so they sort by name.
+const fileTimeFormat = "2006-01-02_15-04-05"
+
+// CorruptDataError reports a data file that exists but can't be parsed.
+type CorruptDataError struct {
+	Path string
+	Err  error
+}
+
+func (e *CorruptDataError) Error() string {
+	return fmt.Sprintf("❌ could not parse data file '%s': %v", e.Path, e.Err)
+}
+
+func (e *CorruptDataError) Unwrap() error {
+	return e.Err
+}
+
+// Salvage describes what SalvageState recovered from a corrupted data file.
+type Salvage struct {
+	Days    int  // Days recovered
+	Skipped int  // Days that couldn't be parsed and were left out
+	Partial bool // The file is cut off or malformed part way through, so everything after that point is missing
+}
+
+// newBackupPath returns the path for a new timestamped backup, never one that already exists.
+func newBackupPath(backupDir string) string {
+	stamp := time.Now().Format(fileTimeFormat)
+	backupFilePath := filepath.Join(backupDir, fmt.Sprintf("backup_%s.json", stamp))
+	for n := 2; ; n++ {
+		if _, err := os.Stat(backupFilePath); os.IsNotExist(err) {
+			return backupFilePath
+		}
+		backupFilePath = filepath.Join(backupDir, fmt.Sprintf("backup_%s_%d.json", stamp, n))
+	}
+}
+
+// QuarantineData moves a corrupted data file aside as data.json.corrupt-<timestamp>, returning its new path.
+func QuarantineData(dataPath string) (string, error) {
+	corruptPath := fmt.Sprintf("%s.corrupt-%s", dataPath, time.Now().Format(fileTimeFormat))
+	if err := os.Rename(dataPath, corruptPath); err != nil {
+		return "", fmt.Errorf("❌ could not move corrupted data file aside: %w", err)
+	}
+	return corruptPath, nil
+}
+
+// LatestCorruptFile returns the most recently quarantined data file, if there is one.
+func LatestCorruptFile(dataPath string) (string, bool) {
+	matches, err := filepath.Glob(dataPath + ".corrupt-*")
+	if err != nil || len(matches) == 0 {
+		return "", false
+	}
+	sort.Strings(matches)
+	return matches[len(matches)-1], true
+}
+
+// LatestValidBackup returns the newest backup in backupDir that parses as grain data.
+func LatestValidBackup(backupDir string) (string, error) {
+	entries, err := os.ReadDir(backupDir)
+	if err != nil {
+		return "", fmt.Errorf("❌ could not read backup directory '%s': %w", backupDir, err)
+	}
+
+	type backup struct {
+		path     string
+		modified time.Time
+	}
+	backups := []backup{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backup{filepath.Join(backupDir, entry.Name()), info.ModTime()})
+	}
+	// Newest first, by modification time so renamed or older-style backup names still order correctly
+	sort.Slice(backups, func(i, j int) bool {
+		if !backups[i].modified.Equal(backups[j].modified) {
+			return backups[i].modified.After(backups[j].modified)
+		}
+		return backups[i].path > backups[j].path
+	})
+
+	for _, b := range backups {
+		input, err := os.ReadFile(b.path)
+		if err != nil || len(input) == 0 {
+			continue
+		}
+		var state AppState
+		if json.Unmarshal(input, &state) == nil {
+			return b.path, nil
+		}
+	}
+	return "", fmt.Errorf("no valid backup found in '%s'", backupDir)
+}
+
+// BackupState writes state to a new timestamped backup, for when there's no intact data.json to copy.
+func BackupState(state *AppState, backupDir string) (string, error) {
+	backupFilePath := newBackupPath(backupDir)
+	writable := *state
+	writable.ReadOnly = false
+	if err := SaveState(backupFilePath, &writable); err != nil {
+		return "", err
+	}
+	return backupFilePath, nil
+}
+
+// SalvageState reads whatever it can from a corrupted data file. Each field and each day is parsed
+// on its own, so a bad entry costs only its own day, and a file that breaks off keeps everything
+// before the damage. The salvaged state is read-only.
+func SalvageState(path string, cfg Config) (AppState, Salvage, error) {
+	state := AppState{}
+	salvage := Salvage{}
+
+	input, err := os.ReadFile(path)
+	if err != nil {
+		return state, salvage, fmt.Errorf("❌ could not read data file '%s': %w", path, err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(input))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		salvage.Partial = true
+	} else {
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				salvage.Partial = true
+				break
+			}
+			key, _ := tok.(string)
+			if key == "logs" {
+				if !salvageDays(dec, &state, &salvage) {
+					salvage.Partial = true
+					break
+				}
+				continue
+			}
+			var value json.RawMessage
+			if err := dec.Decode(&value); err != nil {
+				salvage.Partial = true
+				break
+			}
+			field, _ := json.Marshal(map[string]json.RawMessage{key: value})
+			_ = json.Unmarshal(field, &state) // A field of the wrong shape is simply left empty
+		}
+		if !salvage.Partial {
+			if _, err := dec.Token(); err != nil { // Closing brace
+				salvage.Partial = true
+			}
+		}
+	}
+
+	initCollections(&state)
+	state.Config = cfg
+	state.ReadOnly = true
+	return state, salvage, nil
+}
+
+// salvageDays decodes the logs array one day at a time. It reports false if the array itself is damaged.
+func salvageDays(dec *json.Decoder, state *AppState, salvage *Salvage) bool {
+	tok, err := dec.Token()
+	if err != nil {
+		return false
+	}
+	if tok != json.Delim('[') {
+		return tok != json.Delim('{') // null or a stray value is skipped, an object can't be stepped over
+	}
+	for dec.More() {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return false
+		}
+		var day Day
+		if err := json.Unmarshal(raw, &day); err != nil {
+			salvage.Skipped++
+			continue
+		}
+		state.Logs = append(state.Logs, day)
+		salvage.Days++
+	}
+	_, err = dec.Token() // Closing bracket
+	return err == nil
+}
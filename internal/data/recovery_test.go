@@ -0,0 +1,143 @@
+package data
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const (
+	dayOne = `{"date": "2026-09-08", "logs": [{"type": "study", "timestamp": "2026-09-08T09:00:00Z", "amount": 10}]}`
+	dayTwo = `{"date": "2026-09-09", "logs": [{"type": "break", "timestamp": "2026-09-09T09:00:00Z", "amount": 2}]}`
+)
+
+func TestSalvageState(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		want     Salvage
+		wantBest int
+	}{
+		{name: "intact file", input: `{"best_surplus": 7, "logs": [` + dayOne + `, ` + dayTwo + `]}`, want: Salvage{Days: 2}, wantBest: 7},
+		{name: "cut off inside a day", input: `{"best_surplus": 7, "logs": [` + dayOne + `, {"date": "2026-09-09", "logs": [{"type":`, want: Salvage{Days: 1, Partial: true}, wantBest: 7},
+		{name: "cut off after the logs", input: `{"logs": [` + dayOne + `], "best_surplus": 7, "streak"`, want: Salvage{Days: 1, Partial: true}, wantBest: 7},
+		{name: "cut off before the logs", input: `{"best_surplus": 7, "lo`, want: Salvage{Partial: true}, wantBest: 7},
+		{name: "malformed day skipped", input: `{"logs": [` + dayOne + `, {"date": "2026-09-10", "logs": "oops"}, ` + dayTwo + `]}`, want: Salvage{Days: 2, Skipped: 1}},
+		{name: "field of the wrong shape left empty", input: `{"weekly_surplus": [1, 2], "best_surplus": 7, "logs": [` + dayOne + `]}`, want: Salvage{Days: 1}, wantBest: 7},
+		{name: "null logs", input: `{"logs": null, "best_surplus": 7}`, want: Salvage{}, wantBest: 7},
+		{name: "logs of the wrong shape", input: `{"logs": {"date": "2026-09-08"}, "best_surplus": 7}`, want: Salvage{Partial: true}},
+		{name: "not JSON", input: `garbage`, want: Salvage{Partial: true}},
+		{name: "empty file", input: ``, want: Salvage{Partial: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "data.json")
+			if err := os.WriteFile(path, []byte(tt.input), 0644); err != nil {
+				t.Fatal(err)
+			}
+			state, salvage, err := SalvageState(path, Config{WeeklyGoal: 90})
+			if err != nil {
+				t.Fatalf("SalvageState: %v", err)
+			}
+			if salvage != tt.want {
+				t.Errorf("salvage = %+v, want %+v", salvage, tt.want)
+			}
+			if len(state.Logs) != tt.want.Days || state.BestSurplus != tt.wantBest {
+				t.Errorf("state has %d days and best surplus %d, want %d and %d", len(state.Logs), state.BestSurplus, tt.want.Days, tt.wantBest)
+			}
+			if !state.ReadOnly || state.Config.WeeklyGoal != 90 || state.WeeklySurplus == nil || state.Reflections == nil {
+				t.Errorf("salvaged state isn't read-only with config and collections set: %+v", state)
+			}
+		})
+	}
+}
+
+func TestSalvageStateMissingFile(t *testing.T) {
+	if _, _, err := SalvageState(filepath.Join(t.TempDir(), "data.json"), Config{}); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestLatestValidBackup(t *testing.T) {
+	valid := `{"logs": [` + dayOne + `]}`
+	base := time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC)
+	type file struct {
+		name    string
+		content string
+		age     int // Hours before the newest file
+	}
+	tests := []struct {
+		name    string
+		files   []file
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "newest valid",
+			files: []file{{"backup_a.json", valid, 2}, {"backup_b.json", valid, 1}},
+			want:  "backup_b.json",
+		},
+		{
+			name: "skips newer broken, empty and non-JSON files",
+			files: []file{
+				{"backup_old.json", valid, 3},
+				{"backup_good.json", valid, 2},
+				{"backup_cut.json", `{"logs": [`, 1},
+				{"backup_empty.json", ``, 1},
+				{"notes.txt", valid, 0},
+			},
+			want: "backup_good.json",
+		},
+		{
+			name:  "orders by modification time, not name",
+			files: []file{{"backup_2026-09-02_10-00-00.json", valid, 5}, {"renamed.json", valid, 1}},
+			want:  "renamed.json",
+		},
+		{
+			name:  "same time falls back to name",
+			files: []file{{"backup_1.json", valid, 1}, {"backup_2.json", valid, 1}},
+			want:  "backup_2.json",
+		},
+		{name: "only broken backups", files: []file{{"backup_cut.json", `{"logs": [`, 1}}, wantErr: true},
+		{name: "no backups", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.Mkdir(filepath.Join(dir, "newest.json"), 0755); err != nil { // A directory is never picked
+				t.Fatal(err)
+			}
+			for _, f := range tt.files {
+				path := filepath.Join(dir, f.name)
+				if err := os.WriteFile(path, []byte(f.content), 0644); err != nil {
+					t.Fatal(err)
+				}
+				modified := base.Add(-time.Duration(f.age) * time.Hour)
+				if err := os.Chtimes(path, modified, modified); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := LatestValidBackup(dir)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LatestValidBackup: %v", err)
+			}
+			if filepath.Base(got) != tt.want {
+				t.Errorf("got %s, want %s", filepath.Base(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestLatestValidBackupMissingDir(t *testing.T) {
+	if _, err := LatestValidBackup(filepath.Join(t.TempDir(), "backups")); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
@@ -4,8 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"path/filepath"
-	"time"
 )
 
 // LoadState loads the application state from data.json.
@@ -14,6 +12,12 @@ func LoadState(dataPath string, cfg Config) (AppState, error) {
 	var state AppState
 	state.Config = cfg // Attach loaded config
 	state.WeeklySurplus = make(map[string]int)
+	state.Carryover = make(map[string]int)
+	state.DaysOff = []DayOff{}
+	state.Freezes = []Freeze{}
+	state.Targets = []Target{}
+	state.Plan = []PlanBlock{}
+	state.Reflections = make(map[string]Reflection)
 	state.Logs = []Day{}
 	state.UndoStack = []UndoItem{}
 
@@ -35,26 +39,51 @@ func LoadState(dataPath string, cfg Config) (AppState, error) {
 	}
 
 	if err := json.Unmarshal(bytes, &state); err != nil {
-		return state, fmt.Errorf("❌ could not parse data file '%s': %w", dataPath, err)
+		return state, &CorruptDataError{Path: dataPath, Err: err}
 	}
+	initCollections(&state)
 
-	// Ensure maps/slices are initialized if they were null in the JSON
+	state.Config = cfg // Re-attach config as it's not saved in JSON
+	return state, nil
+}
+
+// initCollections ensures maps/slices are initialized if they were null in the JSON.
+func initCollections(state *AppState) {
 	if state.WeeklySurplus == nil {
 		state.WeeklySurplus = make(map[string]int)
 	}
+	if state.Carryover == nil {
+		state.Carryover = make(map[string]int)
+	}
+	if state.DaysOff == nil {
+		state.DaysOff = []DayOff{}
+	}
+	if state.Freezes == nil {
+		state.Freezes = []Freeze{}
+	}
+	if state.Targets == nil {
+		state.Targets = []Target{}
+	}
+	if state.Plan == nil {
+		state.Plan = []PlanBlock{}
+	}
+	if state.Reflections == nil {
+		state.Reflections = make(map[string]Reflection)
+	}
 	if state.Logs == nil {
 		state.Logs = []Day{}
 	}
 	if state.UndoStack == nil {
 		state.UndoStack = []UndoItem{}
 	}
-
-	state.Config = cfg // Re-attach config as it's not saved in JSON
-	return state, nil
 }
 
 // SaveState saves the application state to data.json.
 func SaveState(dataPath string, state *AppState) error {
+	if state.ReadOnly {
+		return fmt.Errorf("❌ read-only mode: this data was salvaged from a corrupted data file, so it isn't saved. Restore a backup with 'grain restore'")
+	}
+
 	// Ensure Config is not marshalled into the JSON data
 	tempCfg := state.Config
 	state.Config = Config{} // Zero out before marshalling
@@ -77,8 +106,7 @@ func BackupData(dataPath, backupDir string) (string, error) {
 		return "", fmt.Errorf("data file '%s' does not exist, nothing to back up", dataPath)
 	}
 
-	backupFileName := fmt.Sprintf("backup_%s.json", time.Now().Format("2006-01-06_15-04-05"))
-	backupFilePath := filepath.Join(backupDir, backupFileName)
+	backupFilePath := newBackupPath(backupDir)
 
 	input, err := os.ReadFile(dataPath)
 	if err != nil {
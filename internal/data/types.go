@@ -1,12 +1,20 @@
 package data
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // Log represents a single study or break entry.
 type Log struct {
-	Type      string    `json:"type"`      // "study" or "break"
-	Timestamp time.Time `json:"timestamp"` // exact time
-	Amount    int       `json:"amount"`    // e.g. +3 or -1
+	Type      string    `json:"type"`               // "study" or "break"
+	Timestamp time.Time `json:"timestamp"`          // exact time
+	Amount    int       `json:"amount"`             // e.g. +3 or -1
+	Borrowed  int       `json:"borrowed,omitempty"` // Break credits taken on loan (break logs only)
+	Repaid    int       `json:"repaid,omitempty"`   // Study credits used to repay a break loan (study logs only)
+	Tag       string    `json:"tag,omitempty"`      // Optional subject, e.g. "physics" (study logs only)
+	Energy    int       `json:"energy,omitempty"`   // Optional 1-5 energy rating (study logs only, 0 = not rated)
+	Focus     int       `json:"focus,omitempty"`    // Optional 1-5 focus rating (study logs only, 0 = not rated)
 }
 
 // Day aggregates logs for a specific calendar date.
@@ -15,6 +23,51 @@ type Day struct {
 	Logs []Log  `json:"logs"`
 }
 
+// StreakRecord describes a run of consecutive weeks or days and when it happened.
+type StreakRecord struct {
+	Length int    `json:"length"` // Number of weeks or days in the run
+	Start  string `json:"start"`  // First date of the run ("YYYY-MM-DD")
+	End    string `json:"end"`    // Last date of the run ("YYYY-MM-DD")
+}
+
+// DayOff marks a date as a holiday or vacation day that doesn't count towards the weekly goal.
+type DayOff struct {
+	Date   string `json:"date"`          // Format: "YYYY-MM-DD"
+	Reason string `json:"reason"`        // e.g. "holiday"
+	UID    string `json:"uid,omitempty"` // Source calendar event UID for imported days, so re-imports stay idempotent
+}
+
+// Freeze records a streak freeze token spent on a missed week.
+type Freeze struct {
+	Week   string    `json:"week"`    // Week ID ("YYYY-WW") the freeze protects
+	UsedAt time.Time `json:"used_at"` // When the token was spent
+	Auto   bool      `json:"auto"`    // Whether grain applied it automatically
+}
+
+// Target is a cumulative study goal with a deadline, e.g. an exam.
+type Target struct {
+	Name    string `json:"name"`          // e.g. "JEE mock"
+	Credits int    `json:"credits"`       // Study credits to reach
+	By      string `json:"by"`            // Deadline ("YYYY-MM-DD"), inclusive
+	Tag     string `json:"tag,omitempty"` // Only count study with this tag, if set
+	Created string `json:"created"`       // Date the target was added; study from then on counts
+}
+
+// PlanBlock is a study session scheduled ahead of time.
+type PlanBlock struct {
+	Date    string `json:"date"`          // Format: "YYYY-MM-DD"
+	Start   string `json:"start"`         // Start time, "HH:MM"
+	End     string `json:"end"`           // End time, "HH:MM"
+	Credits int    `json:"credits"`       // Study credits planned for the block
+	Tag     string `json:"tag,omitempty"` // Optional subject, e.g. "physics"
+}
+
+// Reflection is the end-of-week journal entry for one week.
+type Reflection struct {
+	Text    string    `json:"text"`    // Markdown written in the editor
+	Updated time.Time `json:"updated"` // When it was last saved
+}
+
 // UndoItem stores the necessary information to revert a log action.
 type UndoItem struct {
 	Log     Log    `json:"log"`
@@ -23,18 +76,90 @@ type UndoItem struct {
 
 // AppState holds the entire state of the application.
 type AppState struct {
-	Logs          []Day          `json:"logs"`           // Chronological list of days with logs
-	WeeklySurplus map[string]int `json:"weekly_surplus"` // Key: "YYYY-WW", Value: surplus credits earned that week
-	Streak        int            `json:"streak"`         // Current consecutive weeks meeting the goal
-	BestSurplus   int            `json:"best_surplus"`   // Highest weekly surplus ever achieved
-	UndoStack     []UndoItem     `json:"undo_stack"`     // Stack for undo operations
-	Config        Config         `json:"-"`              // Runtime configuration, not saved in data.json
+	Logs          []Day                 `json:"logs"`           // Chronological list of days with logs
+	WeeklySurplus map[string]int        `json:"weekly_surplus"` // Key: "YYYY-WW", Value: surplus credits earned that week
+	Streak        int                   `json:"streak"`         // Current consecutive weeks meeting the goal
+	DailyStreak   int                   `json:"daily_streak"`   // Current consecutive working days meeting the daily minimum
+	LongestStreak StreakRecord          `json:"longest_streak"` // Longest run of consecutive weeks meeting the goal
+	LongestDaily  StreakRecord          `json:"longest_daily"`  // Longest run of consecutive show-up days
+	BestSurplus   int                   `json:"best_surplus"`   // Highest weekly surplus ever achieved
+	UndoStack     []UndoItem            `json:"undo_stack"`     // Stack for undo operations
+	Carryover     map[string]int        `json:"carryover"`      // Key: "YYYY-WW", Value: unused break credits carried into that week
+	DaysOff       []DayOff              `json:"days_off"`       // Holidays and vacation days, sorted by date
+	Freezes       []Freeze              `json:"freezes"`        // Streak freeze tokens spent, oldest first
+	Targets       []Target              `json:"targets"`        // Deadline goals such as exams
+	Plan          []PlanBlock           `json:"plan"`           // Scheduled study blocks, sorted by date and start time
+	Reflections   map[string]Reflection `json:"reflections"`    // Key: "YYYY-WW", Value: that week's reflection
+	Config        Config                `json:"-"`              // Runtime configuration, not saved in data.json
+	ReadOnly      bool                  `json:"-"`              // Salvaged from a corrupted data file, so never saved over data.json
 }
 
 // Config holds user-specific settings.
 type Config struct {
-	WeeklyGoal int `json:"weekly_goal"` // Target study credits per week
-	BreakStart int `json:"break_start"` // Break credits allocated at the start of each week
+	WeeklyGoal      int            `json:"weekly_goal"`            // Target study credits per week
+	BreakStart      int            `json:"break_start"`            // Break credits allocated at the start of each week
+	WeekStart       string         `json:"week_start"`             // Weekday the week begins on, e.g. "monday"
+	RestDays        []string       `json:"rest_days"`              // Weekdays that don't count towards the goal, e.g. ["sunday"]
+	RestDayPolicy   string         `json:"rest_day_policy"`        // What logging on a rest day does: "refuse", "previous", "next" or "bonus"
+	DailyMinimum    int            `json:"daily_minimum"`          // Study credits needed for a day to count towards the daily streak
+	FreezesPerMonth int            `json:"freezes_per_month"`      // Streak freeze tokens available per calendar month (0 disables)
+	AutoFreeze      bool           `json:"auto_freeze"`            // Spend a token automatically when last week's goal was missed
+	DayStartsAt     string         `json:"day_starts_at"`          // Time a new day begins, e.g. "04:00" for night owls
+	Timezone        string         `json:"timezone"`               // Home IANA time zone, e.g. "Asia/Kolkata"; empty uses the system zone
+	BreakRules      *BreakRules    `json:"break_rules,omitempty"`  // How break credits are earned; nil uses the default surplus rule
+	Rollover        Rollover       `json:"rollover"`               // What happens to unused break credits at the end of a week
+	Debt            Debt           `json:"debt"`                   // Opt-in carry-forward of last week's shortfall into this week's goal
+	BorrowLimit     int            `json:"borrow_limit"`           // Maximum break credits that may be owed at once (0 disables borrowing)
+	GoalHistory     []GoalChange   `json:"goal_history,omitempty"` // Effective-dated goal and break-start changes, oldest first
+	WeekGoals       map[string]int `json:"week_goals,omitempty"`   // Key: "YYYY-WW", Value: goal override for that single week
+}
+
+// GoalChange records a weekly goal and break start that apply from a given week onward.
+type GoalChange struct {
+	From       string `json:"from"`        // First day of the week the change takes effect ("YYYY-MM-DD"; empty means always)
+	WeeklyGoal int    `json:"weekly_goal"` // Target study credits per week
+	BreakStart int    `json:"break_start"` // Break credits allocated at the start of each week
+}
+
+// Debt configures adding a missed week's shortfall to the following week's goal.
+type Debt struct {
+	Enabled bool `json:"enabled"` // Whether shortfalls carry forward at all
+	Percent int  `json:"percent"` // Share of last week's shortfall added to this week's goal
+	Cap     int  `json:"cap"`     // Maximum credits added to a single week (0 = no cap)
+}
+
+// Rollover configures how unused break credits carry into the next week.
+type Rollover struct {
+	Mode         string `json:"mode"`          // "none", "full", "capped" or "decay"
+	Cap          int    `json:"cap"`           // Maximum credits carried in "capped" mode
+	DecayPercent int    `json:"decay_percent"` // Share of unused credits lost each week in "decay" mode
+}
+
+// BreakRules configures how break credits are earned on top of BreakStart.
+type BreakRules struct {
+	SurplusMultiplier float64     `json:"surplus_multiplier"` // Breaks per study credit above the weekly goal
+	EarnEvery         int         `json:"earn_every"`         // Earn EarnBreaks for every N study credits, goal or not (0 disables)
+	EarnBreaks        int         `json:"earn_breaks"`        // Breaks earned per EarnEvery study credits
+	Tiers             []BreakTier `json:"tiers"`              // One-off bonuses for reaching weekly study thresholds
+	MaxPerWeek        int         `json:"max_per_week"`       // Cap on breaks earned per week (0 = no cap)
+}
+
+// UnmarshalJSON fills in the default surplus multiplier when break_rules leaves it out,
+// so a partial block like {"earn_every": 5} doesn't switch the surplus bonus off. An explicit 0 still does.
+func (r *BreakRules) UnmarshalJSON(b []byte) error {
+	type plain BreakRules // Drops this method so the decode below doesn't recurse
+	rules := plain{SurplusMultiplier: DefaultSurplusMultiplier}
+	if err := json.Unmarshal(b, &rules); err != nil {
+		return err
+	}
+	*r = BreakRules(rules)
+	return nil
+}
+
+// BreakTier awards a bonus once a week's study credits reach a threshold.
+type BreakTier struct {
+	Study int `json:"study"` // Weekly study credits needed
+	Bonus int `json:"bonus"` // Break credits awarded once reached
 }
 
 // Constants for log types
@@ -43,5 +168,30 @@ const (
 	LogTypeBreak = "break"
 )
 
+// Rollover modes
+const (
+	RolloverNone   = "none"
+	RolloverFull   = "full"
+	RolloverCapped = "capped"
+	RolloverDecay  = "decay"
+)
+
+// Rest-day policies
+const (
+	RestDayRefuse   = "refuse"   // Reject logs on rest days
+	RestDayPrevious = "previous" // Count rest-day logs towards the week of the preceding working day
+	RestDayNext     = "next"     // Count rest-day logs towards the week of the following working day
+	RestDayBonus    = "bonus"    // Keep rest-day logs as bonus credits that don't affect the goal
+)
+
+// Bounds for the optional energy and focus ratings on study entries
+const (
+	MinRating = 1
+	MaxRating = 5
+)
+
+// DefaultSurplusMultiplier is the number of break credits earned per study credit above the goal.
+const DefaultSurplusMultiplier = 2
+
 // DateFormat defines the standard date format used throughout the app.
 const DateFormat = "2006-01-02" // ISO 8601 format
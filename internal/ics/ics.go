@@ -0,0 +1,213 @@
+package ics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Event is the subset of an iCalendar VEVENT that grain cares about.
+type Event struct {
+	UID          string
+	Summary      string
+	Start        time.Time   // First day (all-day events) or start time
+	End          time.Time   // Exclusive end, as in the iCalendar spec
+	AllDay       bool        // True when DTSTART is a DATE rather than a DATE-TIME
+	RRule        string      // Recurrence rule, empty for a single event
+	ExDates      []time.Time // Occurrences removed from the recurrence rule
+	RecurrenceID time.Time   // Set on an override: the original start of the occurrence it replaces
+}
+
+// Days returns the calendar dates covered by an all-day event.
+func (e Event) Days() []time.Time {
+	days := []time.Time{}
+	for day := e.Start; day.Before(e.End); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+	return days
+}
+
+// Parse reads the VEVENTs from an iCalendar stream. Dates are interpreted in loc.
+func Parse(r io.Reader, loc *time.Location) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	events := []Event{}
+	var current *Event
+	for n, line := range lines {
+		name, params, value := splitProperty(line)
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			current = &Event{}
+		case name == "END" && value == "VEVENT":
+			if current == nil {
+				return nil, fmt.Errorf("line %d: END:VEVENT without BEGIN:VEVENT", n+1)
+			}
+			if current.Start.IsZero() {
+				return nil, fmt.Errorf("event '%s' has no DTSTART", current.UID)
+			}
+			if current.End.IsZero() {
+				// Without DTEND an all-day event lasts a single day
+				current.End = current.Start
+				if current.AllDay {
+					current.End = current.Start.AddDate(0, 0, 1)
+				}
+			}
+			events = append(events, *current)
+			current = nil
+		case current == nil:
+			// Calendar-level properties are ignored
+		case name == "UID":
+			current.UID = value
+		case name == "SUMMARY":
+			current.Summary = unescape(value)
+		case name == "RRULE":
+			current.RRule = strings.ToUpper(value)
+		case name == "EXDATE":
+			for _, v := range strings.Split(value, ",") {
+				t, _, err := parseTime(v, params, loc)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %w", n+1, err)
+				}
+				current.ExDates = append(current.ExDates, t)
+			}
+		case name == "RECURRENCE-ID":
+			t, _, err := parseTime(value, params, loc)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n+1, err)
+			}
+			current.RecurrenceID = t
+		case name == "DTSTART", name == "DTEND":
+			t, allDay, err := parseTime(value, params, loc)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n+1, err)
+			}
+			if name == "DTSTART" {
+				current.Start, current.AllDay = t, allDay
+			} else {
+				current.End = t
+			}
+		}
+	}
+	return events, nil
+}
+
+// unfold joins continuation lines (those starting with a space or tab) onto the previous line.
+func unfold(r io.Reader) ([]string, error) {
+	lines := []string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read calendar: %w", err)
+	}
+	return lines, nil
+}
+
+// splitProperty splits "NAME;PARAM=X:value" into its name, parameters and value.
+func splitProperty(line string) (name string, params map[string]string, value string) {
+	head, value, _ := strings.Cut(line, ":")
+	parts := strings.Split(head, ";")
+	params = map[string]string{}
+	for _, param := range parts[1:] {
+		key, val, _ := strings.Cut(param, "=")
+		params[strings.ToUpper(key)] = strings.Trim(val, `"`)
+	}
+	return strings.ToUpper(parts[0]), params, value
+}
+
+// parseTime parses a DATE or DATE-TIME value, honoring TZID and the UTC "Z" suffix.
+func parseTime(value string, params map[string]string, loc *time.Location) (time.Time, bool, error) {
+	if params["VALUE"] == "DATE" || len(value) == 8 {
+		t, err := time.ParseInLocation("20060102", value, loc)
+		if err != nil {
+			return t, true, fmt.Errorf("invalid date '%s'", value)
+		}
+		return t, true, nil
+	}
+
+	if tzid, ok := params["TZID"]; ok {
+		if tz, err := time.LoadLocation(tzid); err == nil {
+			loc = tz
+		}
+	}
+	if strings.HasSuffix(value, "Z") {
+		loc = time.UTC
+		value = strings.TrimSuffix(value, "Z")
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	if err != nil {
+		return t, false, fmt.Errorf("invalid date-time '%s'", value)
+	}
+	return t, false, nil
+}
+
+// Write serializes events as an iCalendar stream. Timed events are written in UTC,
+// and stamp is used as every event's DTSTAMP.
+func Write(w io.Writer, events []Event, stamp time.Time) error {
+	lines := []string{"BEGIN:VCALENDAR", "VERSION:2.0", "PRODID:-//grain//plan//EN", "CALSCALE:GREGORIAN"}
+	for _, event := range events {
+		lines = append(lines, "BEGIN:VEVENT", "UID:"+event.UID, "DTSTAMP:"+formatTime(stamp))
+		if event.AllDay {
+			lines = append(lines,
+				"DTSTART;VALUE=DATE:"+event.Start.Format("20060102"),
+				"DTEND;VALUE=DATE:"+event.End.Format("20060102"))
+		} else {
+			lines = append(lines, "DTSTART:"+formatTime(event.Start), "DTEND:"+formatTime(event.End))
+		}
+		lines = append(lines, "SUMMARY:"+escape(event.Summary), "END:VEVENT")
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		if _, err := io.WriteString(w, fold(line)+"\r\n"); err != nil {
+			return fmt.Errorf("could not write calendar: %w", err)
+		}
+	}
+	return nil
+}
+
+// formatTime formats a DATE-TIME value in UTC.
+func formatTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// fold splits a content line into 75-octet chunks, never inside a UTF-8 character.
+func fold(line string) string {
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > 75 {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	return b.String()
+}
+
+// escape applies iCalendar TEXT escaping.
+func escape(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+	return replacer.Replace(value)
+}
+
+// unescape reverses iCalendar TEXT escaping.
+func unescape(value string) string {
+	replacer := strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`)
+	return replacer.Replace(value)
+}
@@ -0,0 +1,171 @@
+package ics
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const examCalendar = `BEGIN:VCALENDAR
+VERSION:2.0
+BEGIN:VEVENT
+UID:exam@example.com
+SUMMARY:Mock exam\, physics
+DTSTART;VALUE=DATE:20261103
+DTEND;VALUE=DATE:20261104
+RRULE:FREQ=WEEKLY;COUNT=3
+END:VEVENT
+BEGIN:VEVENT
+UID:exam@example.com
+RECURRENCE-ID;VALUE=DATE:20261110
+SUMMARY:Mock exam (moved)
+DTSTART;VALUE=DATE:20261112
+DTEND;VALUE=DATE:20261113
+END:VEVENT
+BEGIN:VEVENT
+UID:trip@example.com
+SUMMARY:Trip
+DTSTART;VALUE=DATE:20261120
+DTEND;VALUE=DATE:20261123
+END:VEVENT
+BEGIN:VEVENT
+UID:call@example.com
+SUMMARY:Call
+DTSTART:20261105T090000Z
+DTEND:20261105T100000Z
+END:VEVENT
+END:VCALENDAR
+`
+
+func date(t *testing.T, value string) time.Time {
+	t.Helper()
+	d, err := time.ParseInLocation("2006-01-02", value, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestParse(t *testing.T) {
+	events, err := Parse(strings.NewReader(examCalendar), time.UTC)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(events) != 4 {
+		t.Fatalf("got %d events, want 4", len(events))
+	}
+
+	master := events[0]
+	if master.UID != "exam@example.com" || master.Summary != "Mock exam, physics" || !master.AllDay {
+		t.Errorf("master = %+v", master)
+	}
+	if master.RRule != "FREQ=WEEKLY;COUNT=3" || !master.Start.Equal(date(t, "2026-11-03")) || !master.End.Equal(date(t, "2026-11-04")) {
+		t.Errorf("master rule or dates = %q %v %v", master.RRule, master.Start, master.End)
+	}
+	if override := events[1]; !override.RecurrenceID.Equal(date(t, "2026-11-10")) || !override.Start.Equal(date(t, "2026-11-12")) {
+		t.Errorf("override = %+v", override)
+	}
+	if trip := events[2]; len(trip.Days()) != 3 {
+		t.Errorf("trip covers %d days, want 3", len(trip.Days()))
+	}
+	if call := events[3]; call.AllDay || !call.Start.Equal(time.Date(2026, 11, 5, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("call = %+v", call)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for name, input := range map[string]string{
+		"no DTSTART":   "BEGIN:VEVENT\nUID:x\nEND:VEVENT\n",
+		"bad date":     "BEGIN:VEVENT\nDTSTART;VALUE=DATE:2026-11-03\nEND:VEVENT\n",
+		"END without":  "END:VEVENT\n",
+		"bad EXDATE":   "BEGIN:VEVENT\nDTSTART;VALUE=DATE:20261103\nEXDATE;VALUE=DATE:nope\nEND:VEVENT\n",
+		"bad RECUR-ID": "BEGIN:VEVENT\nDTSTART;VALUE=DATE:20261103\nRECURRENCE-ID:nope\nEND:VEVENT\n",
+	} {
+		if _, err := Parse(strings.NewReader(input), time.UTC); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestOccurrences(t *testing.T) {
+	tests := []struct {
+		name    string
+		start   string
+		rule    string
+		exdates []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "single", start: "2026-11-03", want: []string{"2026-11-03"}},
+		{name: "daily count", start: "2026-11-03", rule: "FREQ=DAILY;COUNT=3", want: []string{"2026-11-03", "2026-11-04", "2026-11-05"}},
+		{name: "daily interval until", start: "2026-11-03", rule: "FREQ=DAILY;INTERVAL=2;UNTIL=20261107", want: []string{"2026-11-03", "2026-11-05", "2026-11-07"}},
+		{name: "weekly", start: "2026-11-03", rule: "FREQ=WEEKLY;COUNT=3", want: []string{"2026-11-03", "2026-11-10", "2026-11-17"}},
+		{name: "weekly byday", start: "2026-11-03", rule: "FREQ=WEEKLY;BYDAY=TU,TH;COUNT=4", want: []string{"2026-11-03", "2026-11-05", "2026-11-10", "2026-11-12"}},
+		{name: "weekly byday skips before start", start: "2026-11-04", rule: "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=3", want: []string{"2026-11-04", "2026-11-09", "2026-11-11"}},
+		{name: "biweekly until", start: "2026-11-03", rule: "FREQ=WEEKLY;INTERVAL=2;UNTIL=20261201T000000Z", want: []string{"2026-11-03", "2026-11-17", "2026-12-01"}},
+		{name: "exdate after count", start: "2026-11-03", rule: "FREQ=WEEKLY;COUNT=3", exdates: []string{"2026-11-10"}, want: []string{"2026-11-03", "2026-11-17"}},
+		{name: "monthly skips short months", start: "2026-01-31", rule: "FREQ=MONTHLY;COUNT=3", want: []string{"2026-01-31", "2026-03-31", "2026-05-31"}},
+		{name: "yearly leap day", start: "2024-02-29", rule: "FREQ=YEARLY;COUNT=2", want: []string{"2024-02-29", "2028-02-29"}},
+		{name: "never ends", start: "2026-11-03", rule: "FREQ=WEEKLY", wantErr: true},
+		{name: "unsupported part", start: "2026-11-03", rule: "FREQ=MONTHLY;BYMONTHDAY=3;COUNT=2", wantErr: true},
+		{name: "unsupported freq", start: "2026-11-03", rule: "FREQ=HOURLY;COUNT=2", wantErr: true},
+		{name: "ordinal byday", start: "2026-11-03", rule: "FREQ=WEEKLY;BYDAY=1TU;COUNT=2", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := date(t, tt.start)
+			event := Event{Start: start, End: start.AddDate(0, 0, 1), AllDay: true, RRule: tt.rule}
+			for _, exdate := range tt.exdates {
+				event.ExDates = append(event.ExDates, date(t, exdate))
+			}
+			got, err := event.Occurrences()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Occurrences: %v", err)
+			}
+			gotDates := []string{}
+			for _, occurrence := range got {
+				gotDates = append(gotDates, occurrence.Format("2006-01-02"))
+			}
+			if strings.Join(gotDates, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("got %v, want %v", gotDates, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	start := time.Date(2026, 11, 3, 9, 30, 0, 0, time.UTC)
+	events := []Event{
+		{UID: "a@grain", Summary: "Physics; ch. 3, problems", Start: start, End: start.Add(90 * time.Minute)},
+		{UID: "b@grain", Summary: strings.Repeat("long summary ", 10), Start: date(t, "2026-11-04"), End: date(t, "2026-11-05"), AllDay: true},
+	}
+	var b strings.Builder
+	if err := Write(&b, events, start); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	for _, line := range strings.Split(b.String(), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line longer than 75 octets: %q", line)
+		}
+	}
+
+	parsed, err := Parse(strings.NewReader(b.String()), time.UTC)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(parsed) != len(events) {
+		t.Fatalf("got %d events, want %d", len(parsed), len(events))
+	}
+	for i, want := range events {
+		got := parsed[i]
+		if got.UID != want.UID || got.Summary != want.Summary || !got.Start.Equal(want.Start) || !got.End.Equal(want.End) || got.AllDay != want.AllDay {
+			t.Errorf("event %d: got %+v, want %+v", i, got, want)
+		}
+	}
+}
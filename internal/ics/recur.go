@@ -0,0 +1,171 @@
+package ics
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxOccurrences bounds how far a recurrence rule is expanded.
+const maxOccurrences = 1000
+
+// weekdayCodes maps BYDAY codes to weekdays.
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// Occurrences returns the start of every occurrence of the event: just Start for a single event,
+// or each start its RRULE produces, minus EXDATEs. Rules need COUNT or UNTIL, and apart from
+// BYDAY on weekly rules, BY* parts aren't supported.
+func (e Event) Occurrences() ([]time.Time, error) {
+	if e.RRule == "" {
+		return []time.Time{e.Start}, nil
+	}
+
+	freq, interval, count, until, byDay, err := parseRule(e.RRule, e.Start.Location())
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 && until.IsZero() {
+		return nil, fmt.Errorf("recurrence rule '%s' never ends", e.RRule)
+	}
+
+	starts := []time.Time{}
+	add := func(start time.Time) bool {
+		if !until.IsZero() && start.After(until) {
+			return false
+		}
+		starts = append(starts, start)
+		return count == 0 || len(starts) < count
+	}
+
+	switch freq {
+	case "DAILY":
+		for i := 0; i < maxOccurrences; i++ {
+			if !add(e.Start.AddDate(0, 0, i*interval)) {
+				break
+			}
+		}
+	case "WEEKLY":
+		if len(byDay) == 0 {
+			byDay = []time.Weekday{e.Start.Weekday()}
+		}
+		// Weeks run Monday to Sunday (the default WKST); occurrences before DTSTART don't count
+		weekStart := e.Start.AddDate(0, 0, -((int(e.Start.Weekday()) + 6) % 7))
+	weeks:
+		for i := 0; len(starts) < maxOccurrences; i++ {
+			week := weekStart.AddDate(0, 0, 7*i*interval)
+			for offset := 0; offset < 7; offset++ {
+				day := week.AddDate(0, 0, offset)
+				if day.Before(e.Start) || !containsWeekday(byDay, day.Weekday()) {
+					continue
+				}
+				if !add(day) {
+					break weeks
+				}
+			}
+		}
+	case "MONTHLY", "YEARLY":
+		for i := 0; i < maxOccurrences; i++ {
+			months := i * interval
+			if freq == "YEARLY" {
+				months *= 12
+			}
+			start := e.Start.AddDate(0, months, 0)
+			if start.Day() != e.Start.Day() {
+				continue // The 31st or Feb 29 doesn't exist that month, so there's no occurrence
+			}
+			if !add(start) {
+				break
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported recurrence frequency '%s'", freq)
+	}
+
+	kept := []time.Time{}
+	for _, start := range starts {
+		if !containsTime(e.ExDates, start) {
+			kept = append(kept, start)
+		}
+	}
+	return kept, nil
+}
+
+// At returns the occurrence of the event starting at start, with the same length as the event.
+func (e Event) At(start time.Time) Event {
+	occurrence := e
+	occurrence.Start = start
+	if e.AllDay {
+		// Count whole days so a DST change in between doesn't shift the end
+		days := int(e.End.Sub(e.Start).Round(24*time.Hour) / (24 * time.Hour))
+		occurrence.End = start.AddDate(0, 0, days)
+	} else {
+		occurrence.End = start.Add(e.End.Sub(e.Start))
+	}
+	return occurrence
+}
+
+// parseRule parses the supported parts of an RRULE value.
+func parseRule(rule string, loc *time.Location) (freq string, interval, count int, until time.Time, byDay []time.Weekday, err error) {
+	interval = 1
+	for _, part := range strings.Split(rule, ";") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "FREQ":
+			freq = value
+		case "INTERVAL":
+			if interval, err = strconv.Atoi(value); err != nil || interval < 1 {
+				return freq, interval, count, until, byDay, fmt.Errorf("invalid INTERVAL '%s'", value)
+			}
+		case "COUNT":
+			if count, err = strconv.Atoi(value); err != nil || count < 1 {
+				return freq, interval, count, until, byDay, fmt.Errorf("invalid COUNT '%s'", value)
+			}
+		case "UNTIL":
+			if until, _, err = parseTime(value, nil, loc); err != nil {
+				return freq, interval, count, until, byDay, err
+			}
+		case "BYDAY":
+			for _, code := range strings.Split(value, ",") {
+				weekday, ok := weekdayCodes[code]
+				if !ok {
+					return freq, interval, count, until, byDay, fmt.Errorf("unsupported BYDAY '%s'", code)
+				}
+				byDay = append(byDay, weekday)
+			}
+		case "WKST":
+			if value != "MO" {
+				return freq, interval, count, until, byDay, fmt.Errorf("unsupported WKST '%s'", value)
+			}
+		default:
+			return freq, interval, count, until, byDay, fmt.Errorf("unsupported recurrence rule part '%s'", key)
+		}
+	}
+	if len(byDay) > 0 && freq != "WEEKLY" {
+		return freq, interval, count, until, byDay, fmt.Errorf("BYDAY is only supported on weekly rules")
+	}
+	return freq, interval, count, until, byDay, nil
+}
+
+// containsWeekday reports whether day is one of days.
+func containsWeekday(days []time.Weekday, day time.Weekday) bool {
+	for _, d := range days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// containsTime reports whether t is one of times.
+func containsTime(times []time.Time, t time.Time) bool {
+	for _, other := range times {
+		if other.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
@@ -10,22 +10,85 @@ import (
 )
 
 // AddLog records a new study or break log.
+// Break logs go through the same balance check as AddBreak, without borrowing.
 func AddLog(state *data.AppState, logType string, amount int, timestamp time.Time) error {
-	if timestamp.Weekday() == time.Sunday {
-		return fmt.Errorf("logging is disabled on Sundays 🧘")
+	if logType == data.LogTypeBreak {
+		_, err := AddBreak(state, amount, timestamp, false)
+		return err
 	}
+	return addLog(state, data.Log{Type: logType, Timestamp: timestamp, Amount: amount})
+}
+
+// AddStudy records a prepared study entry, e.g. one carrying a tag or ratings.
+func AddStudy(state *data.AppState, entry data.Log) error {
+	entry.Type = data.LogTypeStudy
+	if err := validateRating("energy", entry.Energy); err != nil {
+		return err
+	}
+	if err := validateRating("focus", entry.Focus); err != nil {
+		return err
+	}
+	return addLog(state, entry)
+}
+
+// validateRating checks an optional rating; 0 means the entry wasn't rated.
+func validateRating(name string, rating int) error {
+	if rating != 0 && (rating < data.MinRating || rating > data.MaxRating) {
+		return fmt.Errorf("%s rating must be between %d and %d", name, data.MinRating, data.MaxRating)
+	}
+	return nil
+}
+
+// AddBreak records a break after checking it against the week's available break credits.
+// With borrow set, a shortfall is taken as a loan up to Config.BorrowLimit; it returns the amount borrowed.
+func AddBreak(state *data.AppState, amount int, timestamp time.Time, borrow bool) (int, error) {
 	if amount <= 0 {
-		return fmt.Errorf("log amount must be positive")
+		return 0, fmt.Errorf("log amount must be positive")
+	}
+
+	// Rest-day breaks are drawn from the week they count towards, or under the bonus policy their own week
+	logicalDay := timeutil.LogicalDay(timestamp, state.Config)
+	weekStart, _ := timeutil.GetWeekBoundsForDate(logicalDay, state.Config)
+	if credited, ok := CreditWeekStart(logicalDay, state.Config); ok {
+		weekStart = credited
+	}
+	available := SummarizeWeek(state, weekStart).Available
+	borrowed := 0
+	if amount > available {
+		if !borrow {
+			return 0, fmt.Errorf("not enough break credits (need %d, have %d)", amount, available)
+		}
+		borrowed = amount - available
+		owed := OutstandingLoan(state)
+		if owed+borrowed > state.Config.BorrowLimit {
+			return 0, fmt.Errorf("cannot borrow %d break credits (already owe %d, limit %d)", borrowed, owed, state.Config.BorrowLimit)
+		}
 	}
 
-	day := timeutil.GetOrCreateDayLogs(state, timestamp)
+	if err := addLog(state, data.Log{Type: data.LogTypeBreak, Timestamp: timestamp, Amount: amount, Borrowed: borrowed}); err != nil {
+		return 0, err
+	}
+	return borrowed, nil
+}
+
+// addLog validates and stores a log entry. Study credits first repay any outstanding break loan.
+func addLog(state *data.AppState, newLog data.Log) error {
+	logicalDay := timeutil.LogicalDay(newLog.Timestamp, state.Config)
+	if timeutil.IsRestDay(logicalDay, state.Config) && restDayPolicy(state.Config) == data.RestDayRefuse {
+		return fmt.Errorf("logging is disabled on %ss, it's a rest day 🧘 (set rest_day_policy to log anyway)", logicalDay.Weekday())
+	}
+	if newLog.Amount <= 0 {
+		return fmt.Errorf("log amount must be positive")
+	}
 
-	newLog := data.Log{
-		Type:      logType,
-		Timestamp: timestamp,
-		Amount:    amount,
+	// Store timestamps in the home time zone so entries group the same way wherever they were logged
+	newLog.Timestamp = newLog.Timestamp.In(timeutil.Location(state.Config))
+	if newLog.Type == data.LogTypeStudy {
+		newLog.Repaid = min(OutstandingLoan(state), newLog.Amount)
 	}
 
+	day := timeutil.GetOrCreateDayLogs(state, newLog.Timestamp)
+
 	day.Logs = append(day.Logs, newLog)
 	// Ensure logs within the day are sorted by timestamp
 	sort.SliceStable(day.Logs, func(i, j int) bool {
@@ -38,12 +101,26 @@ func AddLog(state *data.AppState, logType string, amount int, timestamp time.Tim
 		DayDate: day.Date,
 	})
 
-	// Recalculate stats after adding log
-	RecalculateWeeklyStats(state, timeutil.GetWeekID(timestamp))
+	// A log can change later weeks too, through debt and rollover, so recalculate them all
+	RecalculateOverallStats(state)
 
 	return nil
 }
 
+// OutstandingLoan returns the break credits borrowed and not yet repaid by later study.
+func OutstandingLoan(state *data.AppState) int {
+	owed := 0
+	for _, day := range state.Logs {
+		for _, log := range day.Logs {
+			owed += log.Borrowed - log.Repaid
+		}
+	}
+	if owed < 0 {
+		return 0
+	}
+	return owed
+}
+
 // UndoLastAction reverts the most recent log action.
 func UndoLastAction(state *data.AppState) (*data.Log, error) {
 	if len(state.UndoStack) == 0 {
@@ -58,7 +135,7 @@ func UndoLastAction(state *data.AppState) (*data.Log, error) {
 	day, found := timeutil.GetDayLogs(state, lastUndoItem.DayDate)
 	if !found {
 		// This should theoretically not happen if data is consistent
-		return nil, fmt.Errorf("internal error: cannot find day log '%s' for undo", lastUndoItem.DayDate)
+		return nil, fmt.Errorf("internal error: cannot find day log '%s' for undo (run 'grain doctor --fix')", lastUndoItem.DayDate)
 	}
 
 	// Find and remove the specific log entry from the day
@@ -73,7 +150,7 @@ func UndoLastAction(state *data.AppState) (*data.Log, error) {
 
 	if originalLogIndex == -1 {
 		// This should also not happen if the undo stack is correct
-		return nil, fmt.Errorf("internal error: cannot find log entry to undo in day '%s'", lastUndoItem.DayDate)
+		return nil, fmt.Errorf("internal error: cannot find log entry to undo in day '%s' (run 'grain doctor --fix')", lastUndoItem.DayDate)
 	}
 
 	// Remove the log entry
@@ -84,10 +161,7 @@ func UndoLastAction(state *data.AppState) (*data.Log, error) {
 		RemoveDay(state, lastUndoItem.DayDate)
 	}
 
-	// Recalculate stats for the affected week
-	undoneLogTime, _ := time.Parse(data.DateFormat, lastUndoItem.DayDate)
-	RecalculateWeeklyStats(state, timeutil.GetWeekID(undoneLogTime))
-	RecalculateOverallStats(state) // Recalculate overall stats like streak
+	RecalculateOverallStats(state) // Recalculate surplus and overall stats like streak
 
 	return &lastUndoItem.Log, nil
 }
@@ -105,182 +179,51 @@ func RemoveDay(state *data.AppState, dateStr string) {
 
 // CalculateCurrentWeekStats computes study credits, break credits used, and available breaks for the current week.
 func CalculateCurrentWeekStats(state *data.AppState) (studyCredits, breaksUsed, breaksAvailable int) {
-	now := time.Now()
-	startOfWeek, endOfWeek := timeutil.GetWeekBounds(now)
-	weekID := timeutil.GetWeekID(now)
-
-	studyCredits = 0
-	breaksUsed = 0
-
-	for _, day := range state.Logs {
-		dayDate, err := time.Parse(data.DateFormat, day.Date)
-		if err != nil {
-			continue // Skip invalid date formats
-		}
+	summary := CurrentWeekSummary(state)
 
-		// Check if the day falls within the current week (inclusive)
-		if (dayDate.Equal(startOfWeek) || dayDate.After(startOfWeek)) && (dayDate.Equal(endOfWeek) || dayDate.Before(endOfWeek)) {
-			if dayDate.Weekday() != time.Sunday { // Exclude Sunday
-				for _, log := range day.Logs {
-					if log.Type == data.LogTypeStudy {
-						studyCredits += log.Amount
-					} else if log.Type == data.LogTypeBreak {
-						breaksUsed += log.Amount
-					}
-				}
-			}
-		}
-	}
-
-	// Calculate available breaks
-	surplus := 0
-	if studyCredits > state.Config.WeeklyGoal {
-		surplus = (studyCredits - state.Config.WeeklyGoal) * 2
-	}
+	// Keep the surplus map in step with what the rules engine says right now
+	storeWeekSurplus(state, summary.ID, summary.Earned)
 
-	// Get surplus from the map, default to 0 if not present
-	currentWeekSurplus := state.WeeklySurplus[weekID]
-	if currentWeekSurplus < 0 {
-		currentWeekSurplus = 0 // Ensure surplus isn't negative in calculation
-	}
-
-	// Available breaks = Starting breaks + Surplus earned this week - Breaks used
-	breaksAvailable = state.Config.BreakStart + currentWeekSurplus - breaksUsed
-
-	// Cap available breaks at the start-of-week amount if no surplus earned yet
-	// This logic might need refinement depending on exact desired behavior with surplus carry-over
-	if surplus == 0 && breaksAvailable > state.Config.BreakStart {
-		// breaksAvailable = state.Config.BreakStart // Simple cap, adjust if needed
-	} else if breaksAvailable < 0 {
-		breaksAvailable = 0 // Cannot have negative available breaks
-	}
-
-	// Update state surplus map (ensure it reflects calculated surplus)
-	if surplus != currentWeekSurplus {
-		state.WeeklySurplus[weekID] = surplus
-		// Check if this new surplus is the best ever
-		if surplus > state.BestSurplus {
-			state.BestSurplus = surplus
-		}
-	}
-
-	return studyCredits, breaksUsed, breaksAvailable
+	return summary.Study, summary.BreaksUsed, summary.Available
 }
 
 // RecalculateWeeklyStats recalculates surplus for a specific week.
 func RecalculateWeeklyStats(state *data.AppState, weekID string) {
-	var weekStartTime time.Time
-	fmt.Sscanf(weekID, "%d-%d", &weekStartTime)
-	// Need to parse weekID back to a time to get bounds accurately
-	year, weekNum := 0, 0
-	_, err := fmt.Sscanf(weekID, "%d-%d", &year, &weekNum)
+	startOfWeek, err := timeutil.WeekStartFromID(weekID, state.Config)
 	if err != nil {
 		fmt.Printf("Error parsing week ID '%s': %v\n", weekID, err)
 		return
 	}
 
-	// Find a Monday within that ISO week and year
-	t := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
-	for t.Weekday() != time.Monday {
-		t = t.AddDate(0, 0, 1)
-	}
-	t = t.AddDate(0, 0, (weekNum-1)*7)
-	// Adjust if the first day calculation was off due to year boundary
-	yCheck, wCheck := t.ISOWeek()
-	if yCheck != year || wCheck != weekNum {
-		// Try adjusting - this can be complex around year ends
-		// A simpler approach might be needed if this fails often
-		diff := (weekNum - wCheck)
-		t = t.AddDate(0, 0, diff*7)
-	}
-
-	startOfWeek, endOfWeek := timeutil.GetWeekBounds(t)
-
-	studyCredits := 0
-	for _, day := range state.Logs {
-		dayDate, err := time.Parse(data.DateFormat, day.Date)
-		if err != nil {
-			continue
-		}
-		if (dayDate.Equal(startOfWeek) || dayDate.After(startOfWeek)) && (dayDate.Equal(endOfWeek) || dayDate.Before(endOfWeek)) {
-			if dayDate.Weekday() != time.Sunday {
-				for _, log := range day.Logs {
-					if log.Type == data.LogTypeStudy {
-						studyCredits += log.Amount
-					}
-				}
-			}
-		}
-	}
-
-	surplus := 0
-	if studyCredits >= state.Config.WeeklyGoal {
-		surplus = (studyCredits - state.Config.WeeklyGoal) * 2
-	}
+	summary := SummarizeWeek(state, startOfWeek)
+	storeWeekSurplus(state, weekID, summary.Earned)
+}
 
-	state.WeeklySurplus[weekID] = surplus
-	if surplus > state.BestSurplus {
-		state.BestSurplus = surplus
-	}
+// storeWeekSurplus stores one week's surplus and raises the best surplus if it beats it.
+// Only RecalculateOverallStats lowers the best, since that takes every week recomputed.
+func storeWeekSurplus(state *data.AppState, weekID string, earned int) {
+	state.WeeklySurplus[weekID] = earned
+	state.BestSurplus = max(state.BestSurplus, earned)
 }
 
-// RecalculateOverallStats updates streak and potentially other long-term stats.
+// RecalculateOverallStats updates every week's surplus, streaks and other long-term stats, recomputed from the logs.
 func RecalculateOverallStats(state *data.AppState) {
-	now := time.Now()
-	currentWeekID := timeutil.GetWeekID(now)
-	currentStreak := 0
-
-	// Iterate backwards from the week before the current one
-	checkTime := now.AddDate(0, 0, -7)
-
-	for {
-		weekID := timeutil.GetWeekID(checkTime)
-		if weekID == currentWeekID { // Should not happen with initial -7 days, but safety check
-			break
-		}
+	RecalculateCarryover(state) // Rolled-over breaks depend on every earlier week
 
-		// Calculate study credits for this past week
-		startOfWeek, endOfWeek := timeutil.GetWeekBounds(checkTime)
-		studyCredits := 0
-		foundLogs := false
-		for _, day := range state.Logs {
-			dayDate, err := time.Parse(data.DateFormat, day.Date)
-			if err != nil {
-				continue
-			}
-			if (dayDate.Equal(startOfWeek) || dayDate.After(startOfWeek)) && (dayDate.Equal(endOfWeek) || dayDate.Before(endOfWeek)) {
-				if dayDate.Weekday() != time.Sunday {
-					foundLogs = true
-					for _, log := range day.Logs {
-						if log.Type == data.LogTypeStudy {
-							studyCredits += log.Amount
-						}
-					}
-				}
-			}
-		}
+	// Days off, goal changes, debt and config edits all move earlier weeks' surplus, so none is left stale
+	state.WeeklySurplus = weeklySurplus(state)
 
-		// If no logs found for the week OR goal not met, streak breaks
-		if !foundLogs || studyCredits < state.Config.WeeklyGoal {
-			break
-		}
+	currentWeeks, longestWeeks := WeeklyStreaks(state)
+	state.Streak = currentWeeks.Length
+	state.LongestStreak = longestWeeks
 
-		// Goal met for this week, increment streak
-		currentStreak++
+	currentDays, longestDays := DailyStreaks(state)
+	state.DailyStreak = currentDays.Length
+	state.LongestDaily = longestDays
 
-		// Move to the previous week
-		checkTime = checkTime.AddDate(0, 0, -7)
-
-		// Safety break: Avoid infinite loops if data is very old or sparse
-		if len(state.Logs) > 0 && checkTime.Before(time.Now().AddDate(-5, 0, 0)) { // Check up to 5 years back
-			break
-		}
-		if len(state.Logs) == 0 { // No logs, no streak
-			break
-		}
-	}
-
-	state.Streak = currentStreak
+	// Recomputed rather than only ever raised, so undone entries don't leave a stale record behind
+	_, bestSurplus := bestWeeks(state)
+	state.BestSurplus = bestSurplus.Value
 }
 
 // CalculateTotalStats computes overall totals.
@@ -300,20 +243,16 @@ func CalculateTotalStats(state *data.AppState) (totalStudy, totalBreaks, totalEn
 
 // ResetWeekData clears logs for the current week and resets surplus.
 func ResetWeekData(state *data.AppState) error {
-	now := time.Now()
-	startOfWeek, endOfWeek := timeutil.GetWeekBounds(now)
-	currentWeekID := timeutil.GetWeekID(now)
+	now := timeutil.Now(state.Config)
+	startOfWeek, endOfWeek := timeutil.GetWeekBounds(now, state.Config)
+	currentWeekID := timeutil.GetWeekID(now, state.Config)
+	first := startOfWeek.Format(data.DateFormat)
+	last := endOfWeek.Format(data.DateFormat)
 
 	newLogs := []data.Day{}
 	for _, day := range state.Logs {
-		dayDate, err := time.Parse(data.DateFormat, day.Date)
-		if err != nil {
-			newLogs = append(newLogs, day) // Keep days with invalid dates? Or log error?
-			continue
-		}
-
 		// Keep the day only if it's outside the current week
-		if dayDate.Before(startOfWeek) || dayDate.After(endOfWeek) {
+		if day.Date < first || day.Date > last {
 			newLogs = append(newLogs, day)
 		}
 	}
@@ -0,0 +1,132 @@
+package logic
+
+import (
+	"testing"
+	"time"
+
+	"grain/internal/data"
+	"grain/internal/timeutil"
+)
+
+// at returns hour o'clock UTC on date.
+func at(t *testing.T, date string, hour int) time.Time {
+	t.Helper()
+	day, err := time.Parse(data.DateFormat, date)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return day.Add(time.Duration(hour) * time.Hour)
+}
+
+// weekOf summarizes the week starting on date.
+func weekOf(t *testing.T, state *data.AppState, date string) WeekSummary {
+	t.Helper()
+	start, err := timeutil.ParseDate(date, state.Config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return SummarizeWeek(state, start)
+}
+
+func TestAddBreakBorrowing(t *testing.T) {
+	tests := []struct {
+		name         string
+		limit        int
+		owed         int // Borrowed the week before and not yet repaid
+		amount       int
+		borrow       bool
+		wantBorrowed int
+		wantErr      bool
+	}{
+		{name: "within the balance", amount: 12, wantBorrowed: 0},
+		{name: "short without borrowing", limit: 5, amount: 13, wantErr: true},
+		{name: "borrow up to the limit", limit: 5, amount: 17, borrow: true, wantBorrowed: 5},
+		{name: "borrow past the limit", limit: 5, amount: 18, borrow: true, wantErr: true},
+		{name: "earlier loan counts towards the limit", limit: 5, owed: 3, amount: 15, borrow: true, wantErr: true},
+		{name: "earlier loan within the limit", limit: 6, owed: 3, amount: 15, borrow: true, wantBorrowed: 3},
+		{name: "borrowing only what's short", limit: 5, amount: 10, borrow: true, wantBorrowed: 0},
+		{name: "non-positive amount", limit: 5, amount: 0, borrow: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := newTestState()
+			state.Config.BorrowLimit = tt.limit
+			if tt.owed > 0 {
+				state.Logs = []data.Day{{Date: "2026-09-01", Logs: []data.Log{
+					{Type: data.LogTypeBreak, Timestamp: at(t, "2026-09-01", 10), Amount: 12 + tt.owed, Borrowed: tt.owed},
+				}}}
+			}
+
+			borrowed, err := AddBreak(state, tt.amount, at(t, "2026-09-08", 10), tt.borrow)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, borrowed %d", borrowed)
+				}
+				if _, found := timeutil.GetDayLogs(state, "2026-09-08"); found {
+					t.Errorf("a refused break was logged")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AddBreak: %v", err)
+			}
+			if borrowed != tt.wantBorrowed || OutstandingLoan(state) != tt.owed+tt.wantBorrowed {
+				t.Errorf("borrowed %d (owing %d), want %d (owing %d)", borrowed, OutstandingLoan(state), tt.wantBorrowed, tt.owed+tt.wantBorrowed)
+			}
+			if available := weekOf(t, state, "2026-09-07").Available; tt.wantBorrowed > 0 && available != 0 {
+				t.Errorf("available after borrowing = %d, want 0", available)
+			}
+		})
+	}
+}
+
+func TestLoanRepayment(t *testing.T) {
+	state := newTestState()
+	state.Config.BorrowLimit = 10
+	if _, err := AddBreak(state, 17, at(t, "2026-09-08", 10), true); err != nil {
+		t.Fatalf("AddBreak: %v", err)
+	}
+
+	steps := []struct {
+		name       string
+		date       string
+		amount     int
+		undo       bool // Undo the last entry instead of logging study
+		wantRepaid int  // Repaid by the new entry
+		wantOwed   int
+		week       string // Week whose study is checked
+		wantStudy  int
+	}{
+		{name: "study repays the loan first", date: "2026-09-09", amount: 3, wantRepaid: 3, wantOwed: 2, week: "2026-09-07", wantStudy: 0},
+		{name: "repayment carries into the next week", date: "2026-09-15", amount: 10, wantRepaid: 2, wantOwed: 0, week: "2026-09-14", wantStudy: 8},
+		{name: "study after repaying counts in full", date: "2026-09-16", amount: 4, wantRepaid: 0, wantOwed: 0, week: "2026-09-14", wantStudy: 12},
+		{name: "undoing plain study", undo: true, wantOwed: 0, week: "2026-09-14", wantStudy: 8},
+		{name: "undoing a repaying entry reopens the loan", undo: true, wantOwed: 2, week: "2026-09-14", wantStudy: 0},
+		{name: "study repays again after the undo", date: "2026-09-17", amount: 5, wantRepaid: 2, wantOwed: 0, week: "2026-09-14", wantStudy: 3},
+	}
+	for _, step := range steps {
+		if step.undo {
+			if _, err := UndoLastAction(state); err != nil {
+				t.Fatalf("%s: UndoLastAction: %v", step.name, err)
+			}
+		} else {
+			if err := AddStudy(state, data.Log{Timestamp: at(t, step.date, 10), Amount: step.amount}); err != nil {
+				t.Fatalf("%s: AddStudy: %v", step.name, err)
+			}
+			if repaid := state.UndoStack[len(state.UndoStack)-1].Log.Repaid; repaid != step.wantRepaid {
+				t.Errorf("%s: repaid %d, want %d", step.name, repaid, step.wantRepaid)
+			}
+		}
+		if owed := OutstandingLoan(state); owed != step.wantOwed {
+			t.Errorf("%s: owing %d, want %d", step.name, owed, step.wantOwed)
+		}
+		if study := weekOf(t, state, step.week).Study; study != step.wantStudy {
+			t.Errorf("%s: week of %s study = %d, want %d", step.name, step.week, study, step.wantStudy)
+		}
+	}
+
+	// The borrowed credits are spent, not earned back: the week's breaks used stay at its allowance
+	if used := weekOf(t, state, "2026-09-07").BreaksUsed; used != 12 {
+		t.Errorf("breaks used in the borrowing week = %d, want 12", used)
+	}
+}
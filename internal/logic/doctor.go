@@ -0,0 +1,195 @@
+package logic
+
+import (
+	"fmt"
+	"sort"
+
+	"grain/internal/data"
+	"grain/internal/timeutil"
+)
+
+// Checks run by the doctor, in the order they're applied
+const (
+	CheckDates   = "dates"   // Day dates that aren't YYYY-MM-DD
+	CheckDays    = "days"    // Duplicate, empty or unsorted days
+	CheckEntries = "entries" // Unknown types, non-positive amounts, unsorted entries
+	CheckRest    = "rest"    // Entries on rest days while rest-day logging is refused
+	CheckUndo    = "undo"    // Undo steps pointing at entries that don't exist
+	CheckSurplus = "surplus" // Stored weekly surplus and best surplus out of step with the logs
+)
+
+// Issue is one inconsistency found in the data file.
+type Issue struct {
+	Check  string
+	Detail string
+}
+
+// Diagnose checks state for inconsistencies without changing it.
+func Diagnose(state *data.AppState) []Issue {
+	return checkState(state, false)
+}
+
+// Repair fixes everything Diagnose reports and returns what it fixed. Entries in days with
+// malformed dates are refiled under the day their timestamp belongs to, duplicate days are merged,
+// invalid and refused rest-day entries are dropped, and the stored surplus is recomputed.
+// Refused rest-day entries don't count towards any week, so dropping them leaves every total as it was.
+func Repair(state *data.AppState) []Issue {
+	return checkState(state, true)
+}
+
+// checkState runs every check against a copy of the logs, fixing the copy as it goes so later
+// checks see a consistent history. With fix set, the repaired copy replaces the state's data.
+func checkState(state *data.AppState, fix bool) []Issue {
+	var issues []Issue
+	report := func(check, format string, args ...any) {
+		issues = append(issues, Issue{Check: check, Detail: fmt.Sprintf(format, args...)})
+	}
+	cfg := state.Config
+
+	// Malformed dates: keep the entries, filed under the day their timestamp falls on
+	days := []data.Day{}
+	var stray []data.Log
+	for _, day := range state.Logs {
+		if _, err := timeutil.ParseDate(day.Date, cfg); err != nil {
+			report(CheckDates, "day '%s' has a malformed date (entries refiled by timestamp)", day.Date)
+			stray = append(stray, day.Logs...)
+			continue
+		}
+		days = append(days, data.Day{Date: day.Date, Logs: append([]data.Log{}, day.Logs...)})
+	}
+
+	if !sort.SliceIsSorted(days, func(i, j int) bool { return days[i].Date < days[j].Date }) {
+		report(CheckDays, "days are not in date order")
+	}
+
+	// Duplicate days are merged into one
+	byDate := map[string]int{}
+	merged := []data.Day{}
+	for _, day := range days {
+		if i, ok := byDate[day.Date]; ok {
+			report(CheckDays, "day %s appears more than once (%d entries merged)", day.Date, len(day.Logs))
+			merged[i].Logs = append(merged[i].Logs, day.Logs...)
+			continue
+		}
+		byDate[day.Date] = len(merged)
+		merged = append(merged, day)
+	}
+	for _, log := range stray {
+		date := timeutil.LogicalDay(log.Timestamp, cfg).Format(data.DateFormat)
+		if _, ok := byDate[date]; !ok {
+			byDate[date] = len(merged)
+			merged = append(merged, data.Day{Date: date})
+		}
+		merged[byDate[date]].Logs = append(merged[byDate[date]].Logs, log)
+	}
+	sort.SliceStable(merged, func(i, j int) bool { return merged[i].Date < merged[j].Date })
+
+	// Entries: drop the invalid ones and refused rest-day ones, keep the rest in time order
+	refuseRest := restDayPolicy(cfg) == data.RestDayRefuse
+	fixed := []data.Day{}
+	for _, day := range merged {
+		date, _ := timeutil.ParseDate(day.Date, cfg)
+		if len(day.Logs) > 0 && refuseRest && timeutil.IsRestDay(date, cfg) {
+			report(CheckRest, "%s is a rest day but has %d entries, which don't count while rest_day_policy is refuse", day.Date, len(day.Logs))
+			continue
+		}
+		logs := []data.Log{}
+		for _, log := range day.Logs {
+			switch {
+			case log.Type != data.LogTypeStudy && log.Type != data.LogTypeBreak:
+				report(CheckEntries, "%s has an entry of unknown type '%s'", day.Date, log.Type)
+			case log.Amount <= 0:
+				report(CheckEntries, "%s has a %s entry of %d credits", day.Date, log.Type, log.Amount)
+			default:
+				logs = append(logs, log)
+			}
+		}
+		if !sort.SliceIsSorted(logs, func(i, j int) bool { return logs[i].Timestamp.Before(logs[j].Timestamp) }) {
+			report(CheckEntries, "%s has entries out of time order", day.Date)
+			sort.SliceStable(logs, func(i, j int) bool { return logs[i].Timestamp.Before(logs[j].Timestamp) })
+		}
+		if len(logs) == 0 {
+			if len(day.Logs) == 0 {
+				report(CheckDays, "day %s has no entries", day.Date)
+			}
+			continue
+		}
+		fixed = append(fixed, data.Day{Date: day.Date, Logs: logs})
+	}
+
+	// Undo steps must point at an entry that still exists; ones filed under the wrong day are redirected
+	undo := []data.UndoItem{}
+	for _, item := range state.UndoStack {
+		date, found := findUndoEntry(fixed, item)
+		switch {
+		case !found:
+			report(CheckUndo, "undo step for %s (%d %s credits) points at an entry that doesn't exist", item.DayDate, item.Log.Amount, item.Log.Type)
+			continue
+		case date != item.DayDate:
+			report(CheckUndo, "undo step for %s points at the wrong day, the entry is on %s", item.DayDate, date)
+			item.DayDate = date
+		}
+		undo = append(undo, item)
+	}
+
+	// Stored surplus must match a fresh recomputation over the repaired logs
+	repaired := *state
+	repaired.Logs = fixed
+	surplus := weeklySurplus(&repaired)
+	for _, weekID := range surplusWeeks(state.WeeklySurplus, surplus) {
+		if stored, expected := state.WeeklySurplus[weekID], surplus[weekID]; stored != expected {
+			report(CheckSurplus, "week %s has a stored surplus of %d, but the logs give %d", weekID, stored, expected)
+		}
+	}
+	_, best := bestWeeks(&repaired)
+	if state.BestSurplus != best.Value {
+		report(CheckSurplus, "best surplus is stored as %d, but the logs give %d", state.BestSurplus, best.Value)
+	}
+
+	if fix {
+		state.Logs = fixed
+		state.UndoStack = undo
+		state.WeeklySurplus = surplus
+		state.BestSurplus = best.Value
+	}
+	return issues
+}
+
+// findUndoEntry finds the day holding the entry an undo step refers to, matching it the way undo does.
+// The step's own day is checked first.
+func findUndoEntry(days []data.Day, item data.UndoItem) (date string, found bool) {
+	matches := func(day data.Day) bool {
+		for _, log := range day.Logs {
+			if log.Timestamp.Equal(item.Log.Timestamp) && log.Amount == item.Log.Amount && log.Type == item.Log.Type {
+				return true
+			}
+		}
+		return false
+	}
+	for _, day := range days {
+		if day.Date == item.DayDate && matches(day) {
+			return day.Date, true
+		}
+	}
+	for _, day := range days {
+		if matches(day) {
+			return day.Date, true
+		}
+	}
+	return "", false
+}
+
+// surplusWeeks returns the week IDs in either map, sorted.
+func surplusWeeks(stored, expected map[string]int) []string {
+	weekIDs := []string{}
+	for weekID := range stored {
+		weekIDs = append(weekIDs, weekID)
+	}
+	for weekID := range expected {
+		if _, ok := stored[weekID]; !ok {
+			weekIDs = append(weekIDs, weekID)
+		}
+	}
+	sort.Strings(weekIDs)
+	return weekIDs
+}
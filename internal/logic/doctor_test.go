@@ -0,0 +1,202 @@
+package logic
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"grain/internal/data"
+)
+
+// entry returns a log at hour o'clock UTC on date.
+func entry(t *testing.T, logType, date string, hour, amount int) data.Log {
+	t.Helper()
+	return data.Log{Type: logType, Timestamp: at(t, date, hour), Amount: amount}
+}
+
+func TestDiagnoseReportsStaleSurplus(t *testing.T) {
+	state := newTestState()
+	state.Logs = []data.Day{
+		{Date: "2026-09-07", Logs: []data.Log{entry(t, data.LogTypeStudy, "2026-09-07", 9, 100)}},
+	}
+	RecalculateOverallStats(state)
+	if issues := Diagnose(state); len(issues) != 0 {
+		t.Fatalf("fresh state: got issues %v", issues)
+	}
+
+	state.WeeklySurplus["2026-37"] = 5
+	state.BestSurplus = 99
+	issues := Diagnose(state)
+	want := []string{
+		"week 2026-37 has a stored surplus of 5, but the logs give 20",
+		"best surplus is stored as 99, but the logs give 20",
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("got issues %v, want %v", issues, want)
+	}
+	for i, issue := range issues {
+		if issue.Check != CheckSurplus || issue.Detail != want[i] {
+			t.Errorf("issue %d = %+v, want %s", i, issue, want[i])
+		}
+	}
+
+	Repair(state)
+	if state.WeeklySurplus["2026-37"] != 20 || state.BestSurplus != 20 {
+		t.Errorf("after repair: week surplus %d, best %d, want 20 and 20", state.WeeklySurplus["2026-37"], state.BestSurplus)
+	}
+}
+
+// dayCounts summarizes days as "date:entries" pairs.
+func dayCounts(days []data.Day) string {
+	counts := []string{}
+	for _, day := range days {
+		counts = append(counts, fmt.Sprintf("%s:%d", day.Date, len(day.Logs)))
+	}
+	return strings.Join(counts, " ")
+}
+
+func TestDiagnoseAndRepair(t *testing.T) {
+	study := func(date string, hour, amount int) data.Log { return entry(t, data.LogTypeStudy, date, hour, amount) }
+	tests := []struct {
+		name       string
+		policy     string
+		days       []data.Day
+		undo       []data.UndoItem
+		wantChecks []string // Checks reported, surplus aside
+		wantDays   string   // Days after repair
+		wantUndo   string   // Undo step days after repair
+	}{
+		{
+			name:     "healthy",
+			days:     []data.Day{{Date: "2026-09-08", Logs: []data.Log{study("2026-09-08", 9, 10)}}},
+			undo:     []data.UndoItem{{Log: study("2026-09-08", 9, 10), DayDate: "2026-09-08"}},
+			wantDays: "2026-09-08:1", wantUndo: "2026-09-08",
+		},
+		{
+			name: "malformed date refiled by timestamp",
+			days: []data.Day{
+				{Date: "2026-09-08", Logs: []data.Log{study("2026-09-08", 9, 10)}},
+				{Date: "08.09.2026", Logs: []data.Log{study("2026-09-08", 11, 5), study("2026-09-09", 11, 5)}},
+			},
+			wantChecks: []string{CheckDates},
+			wantDays:   "2026-09-08:2 2026-09-09:1",
+		},
+		{
+			name: "unsorted days",
+			days: []data.Day{
+				{Date: "2026-09-09", Logs: []data.Log{study("2026-09-09", 9, 10)}},
+				{Date: "2026-09-08", Logs: []data.Log{study("2026-09-08", 9, 10)}},
+			},
+			wantChecks: []string{CheckDays},
+			wantDays:   "2026-09-08:1 2026-09-09:1",
+		},
+		{
+			name: "duplicate days merged",
+			days: []data.Day{
+				{Date: "2026-09-08", Logs: []data.Log{study("2026-09-08", 9, 10)}},
+				{Date: "2026-09-08", Logs: []data.Log{study("2026-09-08", 10, 5)}},
+			},
+			wantChecks: []string{CheckDays},
+			wantDays:   "2026-09-08:2",
+		},
+		{
+			name: "empty day dropped",
+			days: []data.Day{
+				{Date: "2026-09-08"},
+				{Date: "2026-09-09", Logs: []data.Log{study("2026-09-09", 9, 10)}},
+			},
+			wantChecks: []string{CheckDays},
+			wantDays:   "2026-09-09:1",
+		},
+		{
+			name: "invalid entries dropped",
+			days: []data.Day{
+				{Date: "2026-09-08", Logs: []data.Log{
+					{Type: "nap", Timestamp: study("2026-09-08", 8, 1).Timestamp, Amount: 1},
+					study("2026-09-08", 9, 0),
+					study("2026-09-08", 10, 5),
+				}},
+				{Date: "2026-09-09", Logs: []data.Log{study("2026-09-09", 9, -3)}},
+			},
+			wantChecks: []string{CheckEntries, CheckEntries, CheckEntries},
+			wantDays:   "2026-09-08:1",
+		},
+		{
+			name:       "entries out of time order",
+			days:       []data.Day{{Date: "2026-09-08", Logs: []data.Log{study("2026-09-08", 10, 5), study("2026-09-08", 9, 10)}}},
+			wantChecks: []string{CheckEntries},
+			wantDays:   "2026-09-08:2",
+		},
+		{
+			name: "refused rest-day entries removed with their undo step",
+			days: []data.Day{
+				{Date: "2026-09-08", Logs: []data.Log{study("2026-09-08", 9, 10)}},
+				{Date: "2026-09-13", Logs: []data.Log{study("2026-09-13", 9, 10)}},
+			},
+			undo:       []data.UndoItem{{Log: study("2026-09-13", 9, 10), DayDate: "2026-09-13"}},
+			wantChecks: []string{CheckRest, CheckUndo},
+			wantDays:   "2026-09-08:1",
+		},
+		{
+			name:   "rest-day entries kept when the policy counts them",
+			policy: data.RestDayPrevious,
+			days: []data.Day{
+				{Date: "2026-09-08", Logs: []data.Log{study("2026-09-08", 9, 10)}},
+				{Date: "2026-09-13", Logs: []data.Log{study("2026-09-13", 9, 10)}},
+			},
+			wantDays: "2026-09-08:1 2026-09-13:1",
+		},
+		{
+			name:       "undo step for a missing entry removed",
+			days:       []data.Day{{Date: "2026-09-08", Logs: []data.Log{study("2026-09-08", 9, 10)}}},
+			undo:       []data.UndoItem{{Log: study("2026-09-08", 9, 3), DayDate: "2026-09-08"}},
+			wantChecks: []string{CheckUndo},
+			wantDays:   "2026-09-08:1",
+		},
+		{
+			name:       "undo step on the wrong day redirected",
+			days:       []data.Day{{Date: "2026-09-08", Logs: []data.Log{study("2026-09-08", 9, 10)}}},
+			undo:       []data.UndoItem{{Log: study("2026-09-08", 9, 10), DayDate: "2026-09-09"}},
+			wantChecks: []string{CheckUndo},
+			wantDays:   "2026-09-08:1", wantUndo: "2026-09-08",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := newTestState()
+			state.Config.RestDayPolicy = tt.policy
+			state.Logs = tt.days
+			state.UndoStack = tt.undo
+			before, _ := json.Marshal(state)
+
+			checks := []string{}
+			for _, issue := range Diagnose(state) {
+				if issue.Check != CheckSurplus {
+					checks = append(checks, issue.Check)
+				}
+			}
+			if strings.Join(checks, " ") != strings.Join(tt.wantChecks, " ") {
+				t.Errorf("Diagnose checks = %v, want %v", checks, tt.wantChecks)
+			}
+			if after, _ := json.Marshal(state); string(after) != string(before) {
+				t.Errorf("Diagnose changed the state")
+			}
+
+			Repair(state)
+			if got := dayCounts(state.Logs); got != tt.wantDays {
+				t.Errorf("days after repair = %s, want %s", got, tt.wantDays)
+			}
+			undoDays := []string{}
+			for _, item := range state.UndoStack {
+				undoDays = append(undoDays, item.DayDate)
+			}
+			if got := strings.Join(undoDays, " "); got != tt.wantUndo {
+				t.Errorf("undo steps after repair = %s, want %s", got, tt.wantUndo)
+			}
+			if issues := Diagnose(state); len(issues) != 0 {
+				t.Errorf("issues left after repair: %v", issues)
+			}
+		})
+	}
+}
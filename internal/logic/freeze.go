@@ -0,0 +1,90 @@
+package logic
+
+import (
+	"fmt"
+	"time"
+
+	"grain/internal/data"
+	"grain/internal/timeutil"
+)
+
+// IsFrozen reports whether a streak freeze was spent on the given week.
+func IsFrozen(state *data.AppState, weekID string) bool {
+	for _, freeze := range state.Freezes {
+		if freeze.Week == weekID {
+			return true
+		}
+	}
+	return false
+}
+
+// FreezesLeft returns the freeze tokens still available for the calendar month of month.
+// Tokens are charged to the month in which the frozen week starts.
+func FreezesLeft(state *data.AppState, month time.Time) int {
+	used := 0
+	for _, freeze := range state.Freezes {
+		weekStart, err := timeutil.WeekStartFromID(freeze.Week, state.Config)
+		if err != nil {
+			continue
+		}
+		if weekStart.Year() == month.Year() && weekStart.Month() == month.Month() {
+			used++
+		}
+	}
+	left := state.Config.FreezesPerMonth - used
+	if left < 0 {
+		return 0
+	}
+	return left
+}
+
+// UseFreeze spends a freeze token so a missed, completed week doesn't reset the streak.
+func UseFreeze(state *data.AppState, weekID string, auto bool) error {
+	weekStart, err := timeutil.WeekStartFromID(weekID, state.Config)
+	if err != nil {
+		return err
+	}
+	currentStart, _ := timeutil.GetWeekBounds(timeutil.Now(state.Config), state.Config)
+	if !weekStart.Before(currentStart) {
+		return fmt.Errorf("week %s isn't over yet, so there's nothing to freeze", weekID)
+	}
+	if IsFrozen(state, weekID) {
+		return fmt.Errorf("week %s is already frozen", weekID)
+	}
+
+	summary := SummarizeWeek(state, weekStart)
+	if summary.FullyOff() || (summary.HasLogs && summary.Study >= summary.Goal) {
+		return fmt.Errorf("week %s didn't break your streak, no freeze needed", weekID)
+	}
+	if FreezesLeft(state, weekStart) == 0 {
+		return fmt.Errorf("no freeze tokens left for %s (%d per month)", weekStart.Format("January 2006"), state.Config.FreezesPerMonth)
+	}
+
+	state.Freezes = append(state.Freezes, data.Freeze{
+		Week:   weekID,
+		UsedAt: timeutil.Now(state.Config),
+		Auto:   auto,
+	})
+	RecalculateOverallStats(state)
+	return nil
+}
+
+// ApplyAutoFreeze spends a token on last week if auto_freeze is on, the goal was missed,
+// and a streak was running into it. It returns the freeze used, or nil.
+func ApplyAutoFreeze(state *data.AppState) *data.Freeze {
+	if !state.Config.AutoFreeze || state.Config.FreezesPerMonth <= 0 {
+		return nil
+	}
+	currentStart, _ := timeutil.GetWeekBounds(timeutil.Now(state.Config), state.Config)
+	lastStart := currentStart.AddDate(0, 0, -7)
+
+	// Only protect a streak that actually exists
+	if running, _ := weeklyStreaksUntil(state, lastStart); running.Length == 0 {
+		return nil
+	}
+	lastID := timeutil.GetWeekIDForDate(lastStart, state.Config)
+	if err := UseFreeze(state, lastID, true); err != nil {
+		return nil
+	}
+	return &state.Freezes[len(state.Freezes)-1]
+}
@@ -0,0 +1,155 @@
+package logic
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"grain/internal/data"
+	"grain/internal/timeutil"
+)
+
+func TestFreezesLeft(t *testing.T) {
+	state := newTestState()
+	state.Config.FreezesPerMonth = 2
+	// Weeks starting Aug 31, Sep 7 and Sep 14: a freeze is charged to the month its week starts in
+	state.Freezes = []data.Freeze{{Week: "2026-36"}, {Week: "2026-37"}, {Week: "2026-38"}, {Week: "not-a-week"}}
+
+	tests := []struct {
+		month    string
+		perMonth int
+		want     int
+	}{
+		{month: "2026-08-01", perMonth: 2, want: 1},
+		{month: "2026-09-15", perMonth: 2, want: 0},
+		{month: "2026-10-01", perMonth: 2, want: 2},
+		{month: "2025-09-01", perMonth: 2, want: 2}, // Same month, different year
+		{month: "2026-09-01", perMonth: 1, want: 0}, // Overspent months don't go negative
+		{month: "2026-10-01", perMonth: 0, want: 0},
+	}
+	for _, tt := range tests {
+		state.Config.FreezesPerMonth = tt.perMonth
+		if got := FreezesLeft(state, at(t, tt.month, 0)); got != tt.want {
+			t.Errorf("FreezesLeft(%s) with %d per month = %d, want %d", tt.month, tt.perMonth, got, tt.want)
+		}
+	}
+}
+
+// sameMonthWeek returns the ID of another week starting in the same month as weekStart.
+func sameMonthWeek(weekStart time.Time, cfg data.Config) string {
+	for _, offset := range []int{-7, 7, -14, 14, -21, 21} {
+		candidate := weekStart.AddDate(0, 0, offset)
+		if candidate.Month() == weekStart.Month() {
+			return timeutil.GetWeekIDForDate(candidate, cfg)
+		}
+	}
+	return ""
+}
+
+func TestUseFreeze(t *testing.T) {
+	tests := []struct {
+		name     string
+		perMonth int
+		week     int  // Index of the week to freeze among the last three, 3 being the current week
+		frozen   bool // The week already has a freeze
+		spent    bool // Another week in the same month has used the month's token
+		wantErr  string
+	}{
+		{name: "missed week frozen", perMonth: 1, week: 2},
+		{name: "week without logs frozen", perMonth: 1, week: 1},
+		{name: "current week", perMonth: 1, week: 3, wantErr: "isn't over yet"},
+		{name: "already frozen", perMonth: 2, week: 2, frozen: true, wantErr: "already frozen"},
+		{name: "goal met", perMonth: 1, week: 0, wantErr: "no freeze needed"},
+		{name: "freezes disabled", perMonth: 0, week: 2, wantErr: "no freeze tokens left"},
+		{name: "month's tokens used up", perMonth: 1, week: 2, spent: true, wantErr: "no freeze tokens left"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := newTestState()
+			state.Config.RestDays = []string{}
+			state.Config.FreezesPerMonth = tt.perMonth
+			starts := logPastWeeks(t, state, 90, 0, 50)
+			currentStart, _ := timeutil.GetWeekBounds(timeutil.Now(state.Config), state.Config)
+			starts = append(starts, currentStart)
+			weekID := timeutil.GetWeekIDForDate(starts[tt.week], state.Config)
+			if tt.frozen {
+				state.Freezes = append(state.Freezes, data.Freeze{Week: weekID})
+			}
+			if tt.spent {
+				state.Freezes = append(state.Freezes, data.Freeze{Week: sameMonthWeek(starts[tt.week], state.Config)})
+			}
+			before := len(state.Freezes)
+
+			err := UseFreeze(state, weekID, false)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+				if len(state.Freezes) != before {
+					t.Errorf("a refused freeze was recorded: %v", state.Freezes)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UseFreeze: %v", err)
+			}
+			if len(state.Freezes) != before+1 || state.Freezes[before].Week != weekID || state.Freezes[before].Auto {
+				t.Errorf("freezes = %+v, want a manual freeze for %s", state.Freezes, weekID)
+			}
+			if !IsFrozen(state, weekID) {
+				t.Errorf("week %s isn't frozen", weekID)
+			}
+		})
+	}
+}
+
+func TestApplyAutoFreeze(t *testing.T) {
+	tests := []struct {
+		name       string
+		autoFreeze bool
+		perMonth   int
+		study      []int // Study in each of the last three weeks, oldest first
+		spent      bool  // Last week's month has already used its token
+		wantFreeze bool
+	}{
+		{name: "streak kept", autoFreeze: true, perMonth: 1, study: []int{90, 90, 50}, wantFreeze: true},
+		{name: "empty last week frozen", autoFreeze: true, perMonth: 1, study: []int{90, 90, 0}, wantFreeze: true},
+		{name: "auto_freeze off", perMonth: 1, study: []int{90, 90, 50}},
+		{name: "no tokens configured", autoFreeze: true, study: []int{90, 90, 50}},
+		{name: "no streak to protect", autoFreeze: true, perMonth: 1, study: []int{90, 50, 50}},
+		{name: "last week met its goal", autoFreeze: true, perMonth: 1, study: []int{90, 90, 90}},
+		{name: "month's tokens used up", autoFreeze: true, perMonth: 1, study: []int{90, 90, 50}, spent: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := newTestState()
+			state.Config.RestDays = []string{}
+			state.Config.AutoFreeze = tt.autoFreeze
+			state.Config.FreezesPerMonth = tt.perMonth
+			starts := logPastWeeks(t, state, tt.study...)
+			lastStart := starts[len(starts)-1]
+			lastID := timeutil.GetWeekIDForDate(lastStart, state.Config)
+			if tt.spent {
+				state.Freezes = append(state.Freezes, data.Freeze{Week: sameMonthWeek(lastStart, state.Config)})
+			}
+			before := len(state.Freezes)
+
+			freeze := ApplyAutoFreeze(state)
+			if !tt.wantFreeze {
+				if freeze != nil || len(state.Freezes) != before {
+					t.Errorf("unexpected freeze: %+v", state.Freezes)
+				}
+				return
+			}
+			if freeze == nil || freeze.Week != lastID || !freeze.Auto {
+				t.Fatalf("freeze = %+v, want an automatic freeze for %s", freeze, lastID)
+			}
+			if current, _ := WeeklyStreaks(state); current.Length != 2 {
+				t.Errorf("streak after the freeze = %d, want 2", current.Length)
+			}
+			if ApplyAutoFreeze(state) != nil {
+				t.Errorf("the same week was frozen twice")
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package logic
+
+import (
+	"time"
+
+	"grain/internal/data"
+	"grain/internal/timeutil"
+)
+
+// HeatDay is one day in the study heatmap.
+type HeatDay struct {
+	Date   time.Time
+	Study  int  // Study credits logged that day
+	Off    bool // Marked as a day off
+	Rest   bool // A configured rest day
+	Future bool // After today, so nothing to show yet
+}
+
+// Heatmap returns the days of the last weeks weeks, oldest week first, each starting on the configured week start.
+func Heatmap(state *data.AppState, weeks int) [][]HeatDay {
+	today := timeutil.LogicalDay(timeutil.Now(state.Config), state.Config)
+	currentStart, _ := timeutil.GetWeekBoundsForDate(today, state.Config)
+	studyOn := dailyStudy(state)
+
+	heatmap := make([][]HeatDay, 0, weeks)
+	for i := weeks - 1; i >= 0; i-- {
+		weekStart := currentStart.AddDate(0, 0, -7*i)
+		week := make([]HeatDay, 7)
+		for j := range week {
+			date := weekStart.AddDate(0, 0, j)
+			dateStr := date.Format(data.DateFormat)
+			_, off := GetDayOff(state, dateStr)
+			week[j] = HeatDay{
+				Date:   date,
+				Study:  studyOn[dateStr],
+				Off:    off,
+				Rest:   timeutil.IsRestDay(date, state.Config),
+				Future: date.After(today),
+			}
+		}
+		heatmap = append(heatmap, week)
+	}
+	return heatmap
+}
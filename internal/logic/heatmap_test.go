@@ -0,0 +1,49 @@
+package logic
+
+import (
+	"testing"
+	"time"
+
+	"grain/internal/data"
+	"grain/internal/timeutil"
+)
+
+func TestHeatmap(t *testing.T) {
+	state := newTestState()
+	state.Config.RestDays = []string{"sunday"}
+	today := timeutil.LogicalDay(timeutil.Now(state.Config), state.Config)
+	currentStart, _ := timeutil.GetWeekBoundsForDate(today, state.Config)
+	lastWeek := currentStart.AddDate(0, 0, -7)
+	state.Logs = []data.Day{{Date: lastWeek.Format(data.DateFormat), Logs: []data.Log{
+		{Type: data.LogTypeStudy, Timestamp: lastWeek, Amount: 6},
+		{Type: data.LogTypeBreak, Timestamp: lastWeek, Amount: 2},
+		{Type: data.LogTypeStudy, Timestamp: lastWeek, Amount: 3},
+	}}}
+	AddDaysOff(state, lastWeek.AddDate(0, 0, 1), lastWeek.AddDate(0, 0, 2), "trip")
+
+	heatmap := Heatmap(state, 3)
+	if len(heatmap) != 3 {
+		t.Fatalf("got %d weeks, want 3", len(heatmap))
+	}
+	if first := heatmap[0][0].Date; !first.Equal(currentStart.AddDate(0, 0, -14)) {
+		t.Errorf("first day = %s, want the start of the week before last", first.Format(data.DateFormat))
+	}
+
+	week := heatmap[1]
+	if week[0].Study != 9 || week[0].Off {
+		t.Errorf("first day = %+v, want 9 study credits", week[0])
+	}
+	if !week[1].Off || !week[2].Off || week[3].Off {
+		t.Errorf("days off = %v %v %v, want the second and third day only", week[1].Off, week[2].Off, week[3].Off)
+	}
+	for _, day := range week {
+		if day.Rest != (day.Date.Weekday() == time.Sunday) || day.Future {
+			t.Errorf("%s: rest %v, future %v", day.Date.Format(data.DateFormat), day.Rest, day.Future)
+		}
+	}
+	for _, day := range heatmap[2] {
+		if day.Future != day.Date.After(today) {
+			t.Errorf("%s: future = %v", day.Date.Format(data.DateFormat), day.Future)
+		}
+	}
+}
@@ -0,0 +1,409 @@
+package logic
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"grain/internal/data"
+	"grain/internal/timeutil"
+)
+
+// recentBreakWindow is how soon before a study entry a break must have been logged to count as "after a break".
+const recentBreakWindow = 90 * time.Minute
+
+// minRatedEntries is the fewest rated entries a group needs before its averages are worth comparing.
+const minRatedEntries = 3
+
+// RatingGroup averages the energy and focus ratings of the study entries in one bucket.
+type RatingGroup struct {
+	Label         string
+	Entries       int     // Rated entries in the group
+	FocusCount    int     // Entries with a focus rating
+	FocusTotal    int     // Sum of their focus ratings
+	EnergyCount   int     // Entries with an energy rating
+	EnergyTotal   int     // Sum of their energy ratings
+	FocusAverage  float64 // Average focus (0 when none were rated)
+	EnergyAverage float64 // Average energy (0 when none were rated)
+}
+
+// Enough reports whether the group has enough rated entries to compare with others.
+func (g RatingGroup) Enough() bool {
+	return g.Entries >= minRatedEntries
+}
+
+// MoodInsights relates energy and focus ratings to when and how study happened.
+type MoodInsights struct {
+	Rated       int           // Study entries with at least one rating
+	TimeOfDay   []RatingGroup // Morning, afternoon, evening, night
+	Weekdays    []RatingGroup // In week order, starting on the configured week start
+	SessionSize []RatingGroup // By credits logged in the entry
+	AfterBreak  []RatingGroup // With and without a break shortly before
+	// Pearson correlation between session size and focus (NaN when it can't be computed)
+	SizeFocusCorrelation float64
+}
+
+// timeOfDayLabels names the parts of the day entries are bucketed into.
+var timeOfDayLabels = []string{"Morning (5-12)", "Afternoon (12-17)", "Evening (17-22)", "Night (22-5)"}
+
+// sessionSizeLabels names the session size buckets.
+var sessionSizeLabels = []string{"1 credit", "2-3 credits", "4+ credits"}
+
+// AnalyzeMood groups every rated study entry by time of day, weekday, session size
+// and whether a break came shortly before, averaging the ratings in each group.
+func AnalyzeMood(state *data.AppState) MoodInsights {
+	insights := MoodInsights{
+		TimeOfDay:   newRatingGroups(timeOfDayLabels),
+		SessionSize: newRatingGroups(sessionSizeLabels),
+		AfterBreak:  newRatingGroups([]string{"After a break", "No recent break"}),
+	}
+	insights.Weekdays = newRatingGroups(weekdayLabels(state.Config))
+
+	sizes, focuses := []float64{}, []float64{}
+	for _, day := range state.Logs {
+		dayDate, err := timeutil.ParseDate(day.Date, state.Config)
+		if err != nil {
+			continue
+		}
+		lastBreak := time.Time{}
+		for _, log := range day.Logs {
+			if log.Type == data.LogTypeBreak {
+				lastBreak = log.Timestamp
+				continue
+			}
+			if log.Type != data.LogTypeStudy || (log.Focus == 0 && log.Energy == 0) {
+				continue
+			}
+			insights.Rated++
+
+			local := log.Timestamp.In(timeutil.Location(state.Config))
+			insights.TimeOfDay[timeOfDayBucket(local.Hour())].add(log)
+			// Weekdays follow the logical day, so a 1 a.m. session counts towards the evening it belongs to
+			insights.Weekdays[weekdayIndex(dayDate.Weekday(), state.Config)].add(log)
+			insights.SessionSize[sessionSizeBucket(log.Amount)].add(log)
+			if !lastBreak.IsZero() && log.Timestamp.Sub(lastBreak) <= recentBreakWindow {
+				insights.AfterBreak[0].add(log)
+			} else {
+				insights.AfterBreak[1].add(log)
+			}
+
+			if log.Focus > 0 {
+				sizes = append(sizes, float64(log.Amount))
+				focuses = append(focuses, float64(log.Focus))
+			}
+		}
+	}
+	insights.SizeFocusCorrelation = Correlation(sizes, focuses)
+	return insights
+}
+
+// BestFocus returns the group with the highest average focus among those with enough entries.
+func BestFocus(groups []RatingGroup) (RatingGroup, bool) {
+	best, found := RatingGroup{}, false
+	for _, group := range groups {
+		if group.Enough() && group.FocusCount > 0 && (!found || group.FocusAverage > best.FocusAverage) {
+			best, found = group, true
+		}
+	}
+	return best, found
+}
+
+// Correlation returns the Pearson correlation coefficient of xs and ys,
+// or NaN when there are fewer than minRatedEntries pairs or either series is constant.
+func Correlation(xs, ys []float64) float64 {
+	n := len(xs)
+	if n != len(ys) || n < minRatedEntries {
+		return math.NaN()
+	}
+	meanX, meanY := mean(xs), mean(ys)
+	var cov, varX, varY float64
+	for i := range xs {
+		dx, dy := xs[i]-meanX, ys[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return math.NaN()
+	}
+	return cov / math.Sqrt(varX*varY)
+}
+
+// mean returns the arithmetic mean of values (0 for none).
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+	return total / float64(len(values))
+}
+
+// weekOrder returns the weekdays in week order, starting on the configured week start.
+func weekOrder(cfg data.Config) []time.Weekday {
+	days := []time.Weekday{}
+	for i := 0; i < 7; i++ {
+		days = append(days, time.Weekday((int(timeutil.WeekStartDay(cfg))+i)%7))
+	}
+	return days
+}
+
+// weekdayLabels names the weekdays in week order.
+func weekdayLabels(cfg data.Config) []string {
+	labels := []string{}
+	for _, day := range weekOrder(cfg) {
+		labels = append(labels, day.String())
+	}
+	return labels
+}
+
+// weekdayIndex returns a weekday's position in the configured week.
+func weekdayIndex(day time.Weekday, cfg data.Config) int {
+	return (int(day) - int(timeutil.WeekStartDay(cfg)) + 7) % 7
+}
+
+// newRatingGroups creates an empty group for each label.
+func newRatingGroups(labels []string) []RatingGroup {
+	groups := make([]RatingGroup, len(labels))
+	for i, label := range labels {
+		groups[i].Label = label
+	}
+	return groups
+}
+
+// add counts a rated entry towards the group and refreshes its averages.
+func (g *RatingGroup) add(log data.Log) {
+	g.Entries++
+	if log.Focus > 0 {
+		g.FocusCount++
+		g.FocusTotal += log.Focus
+		g.FocusAverage = float64(g.FocusTotal) / float64(g.FocusCount)
+	}
+	if log.Energy > 0 {
+		g.EnergyCount++
+		g.EnergyTotal += log.Energy
+		g.EnergyAverage = float64(g.EnergyTotal) / float64(g.EnergyCount)
+	}
+}
+
+// timeOfDayBucket returns the index into timeOfDayLabels for an hour of the day.
+func timeOfDayBucket(hour int) int {
+	switch {
+	case hour >= 5 && hour < 12:
+		return 0
+	case hour >= 12 && hour < 17:
+		return 1
+	case hour >= 17 && hour < 22:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// sessionSizeBucket returns the index into sessionSizeLabels for an entry's credits.
+func sessionSizeBucket(credits int) int {
+	switch {
+	case credits <= 1:
+		return 0
+	case credits <= 3:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// significanceLevel is the p-value below which a change between periods is called meaningful.
+const significanceLevel = 0.05
+
+// WeekdayStudy summarizes the study logged on one weekday.
+type WeekdayStudy struct {
+	Weekday time.Weekday
+	Days    int     // Working days of this weekday since the first log
+	Total   int     // Study credits logged on them
+	Average float64 // Credits per day
+}
+
+// PeriodComparison compares daily study in the most recent weeks with the weeks before.
+type PeriodComparison struct {
+	Weeks        int     // Length of each period
+	RecentDays   int     // Working days in the recent period
+	PreviousDays int     // Working days in the previous period
+	Recent       float64 // Average study credits per working day, recent period
+	Previous     float64 // Average study credits per working day, previous period
+	PValue       float64 // Two-sided Mann-Whitney U test (NaN when either period is too short)
+}
+
+// Significant reports whether the difference between the periods is unlikely to be chance.
+func (c PeriodComparison) Significant() bool {
+	return !math.IsNaN(c.PValue) && c.PValue < significanceLevel
+}
+
+// HabitInsights describes when and how study happens, from the timestamps of every log.
+type HabitInsights struct {
+	Entries       int            // Study entries
+	TotalStudy    int            // Study credits
+	TotalBreaks   int            // Break credits
+	ByHour        [24]int        // Study credits by hour of day, home time zone
+	Weekdays      []WeekdayStudy // In week order, starting on the configured week start
+	MedianSession float64        // Median credits per study entry
+	Comparison    PeriodComparison
+}
+
+// BreakRatio returns break credits spent per study credit.
+func (h HabitInsights) BreakRatio() float64 {
+	if h.TotalStudy == 0 {
+		return 0
+	}
+	return float64(h.TotalBreaks) / float64(h.TotalStudy)
+}
+
+// MostAndLeastProductive returns the weekdays with the highest and lowest average study.
+func (h HabitInsights) MostAndLeastProductive() (most, least WeekdayStudy, ok bool) {
+	for _, day := range h.Weekdays {
+		if day.Days == 0 {
+			continue
+		}
+		if !ok || day.Average > most.Average {
+			most = day
+		}
+		if !ok || day.Average < least.Average {
+			least = day
+		}
+		ok = true
+	}
+	return most, least, ok
+}
+
+// AnalyzeHabits analyzes every log's timestamp, and compares the last `weeks` weeks of daily study with the weeks before.
+func AnalyzeHabits(state *data.AppState, weeks int) HabitInsights {
+	insights := HabitInsights{Comparison: PeriodComparison{Weeks: weeks, PValue: math.NaN()}}
+	sessions := []float64{}
+	for _, day := range state.Logs {
+		for _, log := range day.Logs {
+			switch log.Type {
+			case data.LogTypeStudy:
+				insights.Entries++
+				insights.TotalStudy += log.Amount
+				insights.ByHour[log.Timestamp.In(timeutil.Location(state.Config)).Hour()] += log.Amount
+				sessions = append(sessions, float64(log.Amount))
+			case data.LogTypeBreak:
+				insights.TotalBreaks += log.Amount
+			}
+		}
+	}
+	insights.MedianSession = median(sessions)
+
+	for _, weekday := range weekOrder(state.Config) {
+		insights.Weekdays = append(insights.Weekdays, WeekdayStudy{Weekday: weekday})
+	}
+	if len(state.Logs) == 0 {
+		return insights
+	}
+	first, err := timeutil.ParseDate(state.Logs[0].Date, state.Config)
+	if err != nil {
+		return insights
+	}
+
+	// Walk every working day since the first log, so days without study count as zero
+	studyOn := dailyStudy(state)
+	today := timeutil.LogicalDay(timeutil.Now(state.Config), state.Config)
+	recentFrom := today.AddDate(0, 0, -7*weeks)
+	previousFrom := recentFrom.AddDate(0, 0, -7*weeks)
+	recent, previous := []float64{}, []float64{}
+	for date := first; !date.After(today); date = date.AddDate(0, 0, 1) {
+		if !isWorkingDay(state, date) {
+			continue
+		}
+		study := studyOn[date.Format(data.DateFormat)]
+		weekday := &insights.Weekdays[weekdayIndex(date.Weekday(), state.Config)]
+		weekday.Days++
+		weekday.Total += study
+
+		// Today is still in progress, so leave it out of the comparison
+		switch {
+		case !date.Before(recentFrom) && date.Before(today):
+			recent = append(recent, float64(study))
+		case !date.Before(previousFrom) && date.Before(recentFrom):
+			previous = append(previous, float64(study))
+		}
+	}
+	for i := range insights.Weekdays {
+		if insights.Weekdays[i].Days > 0 {
+			insights.Weekdays[i].Average = float64(insights.Weekdays[i].Total) / float64(insights.Weekdays[i].Days)
+		}
+	}
+
+	comparison := &insights.Comparison
+	comparison.RecentDays, comparison.PreviousDays = len(recent), len(previous)
+	comparison.Recent, comparison.Previous = mean(recent), mean(previous)
+	comparison.PValue = MannWhitneyP(recent, previous)
+	return insights
+}
+
+// median returns the middle value of values (0 for none).
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// MannWhitneyP returns the two-sided p-value of a Mann-Whitney U test between two samples,
+// using the normal approximation with a tie correction. It needs at least 5 values in each
+// sample and returns NaN otherwise. Unlike a t-test it doesn't assume study is normally
+// distributed, which daily credits (often zero, sometimes large) are not.
+func MannWhitneyP(a, b []float64) float64 {
+	n1, n2 := len(a), len(b)
+	if n1 < 5 || n2 < 5 {
+		return math.NaN()
+	}
+
+	// Rank the pooled values, giving ties their average rank
+	type sample struct {
+		value float64
+		first bool
+	}
+	pooled := make([]sample, 0, n1+n2)
+	for _, v := range a {
+		pooled = append(pooled, sample{v, true})
+	}
+	for _, v := range b {
+		pooled = append(pooled, sample{v, false})
+	}
+	sort.Slice(pooled, func(i, j int) bool { return pooled[i].value < pooled[j].value })
+
+	rankSum, tieTerm := 0.0, 0.0
+	for i := 0; i < len(pooled); {
+		j := i
+		for j < len(pooled) && pooled[j].value == pooled[i].value {
+			j++
+		}
+		rank := float64(i+j+1) / 2 // Average of ranks i+1..j
+		for k := i; k < j; k++ {
+			if pooled[k].first {
+				rankSum += rank
+			}
+		}
+		t := float64(j - i)
+		tieTerm += t*t*t - t
+		i = j
+	}
+
+	n := float64(n1 + n2)
+	u := rankSum - float64(n1*(n1+1))/2
+	meanU := float64(n1*n2) / 2
+	variance := float64(n1*n2) / 12 * ((n + 1) - tieTerm/(n*(n-1)))
+	if variance <= 0 {
+		return 1 // Every value is the same, so there's no difference to find
+	}
+	z := math.Abs(u-meanU) / math.Sqrt(variance)
+	return math.Erfc(z / math.Sqrt2)
+}
@@ -0,0 +1,40 @@
+package logic
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMannWhitneyP(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float64
+		want float64 // NaN when there aren't enough values
+	}{
+		{name: "fully separated", a: []float64{1, 2, 3, 4, 5}, b: []float64{6, 7, 8, 9, 10}, want: 0.009023},
+		{name: "order doesn't matter", a: []float64{10, 8, 6, 9, 7}, b: []float64{5, 3, 1, 2, 4}, want: 0.009023},
+		{name: "interleaved", a: []float64{1, 3, 5, 7, 9}, b: []float64{2, 4, 6, 8, 10}, want: 0.601508},
+		{name: "ties with zero days", a: []float64{0, 0, 1, 2, 5, 0}, b: []float64{3, 0, 4, 6, 2, 8}, want: 0.102470},
+		{name: "identical samples", a: []float64{2, 4, 6, 8, 10}, b: []float64{2, 4, 6, 8, 10}, want: 1},
+		{name: "every value the same", a: []float64{3, 3, 3, 3, 3}, b: []float64{3, 3, 3, 3, 3}, want: 1},
+		{name: "too few values", a: []float64{1, 2, 3, 4}, b: []float64{6, 7, 8, 9, 10}, want: math.NaN()},
+		{name: "empty", want: math.NaN()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MannWhitneyP(tt.a, tt.b)
+			if math.IsNaN(tt.want) {
+				if !math.IsNaN(got) {
+					t.Errorf("MannWhitneyP = %f, want NaN", got)
+				}
+				return
+			}
+			if math.Abs(got-tt.want) > 1e-5 {
+				t.Errorf("MannWhitneyP = %f, want %f", got, tt.want)
+			}
+			if swapped := MannWhitneyP(tt.b, tt.a); math.Abs(swapped-got) > 1e-12 {
+				t.Errorf("swapping the samples changed p from %f to %f", got, swapped)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package logic
+
+import (
+	"sort"
+	"time"
+
+	"grain/internal/data"
+	"grain/internal/ics"
+	"grain/internal/timeutil"
+)
+
+// AddDaysOff marks every date in [from, to] as a day off with the given reason.
+// Dates that are already off get the new reason. It returns the number of dates recorded.
+func AddDaysOff(state *data.AppState, from, to time.Time, reason string) int {
+	count := 0
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		dateStr := date.Format(data.DateFormat)
+		if i := dayOffIndex(state, dateStr); i >= 0 {
+			state.DaysOff[i].Reason = reason
+		} else {
+			state.DaysOff = append(state.DaysOff, data.DayOff{Date: dateStr, Reason: reason})
+		}
+		count++
+	}
+	sort.SliceStable(state.DaysOff, func(i, j int) bool {
+		return state.DaysOff[i].Date < state.DaysOff[j].Date
+	})
+	RecalculateOverallStats(state)
+	return count
+}
+
+// ImportDaysOff records the all-day events of a calendar as days off, matched by UID.
+// Days previously imported from an event are replaced, so importing the same calendar twice changes nothing.
+// Recurring events are expanded, with overrides of single occurrences (RECURRENCE-ID) replacing them.
+// Dates already taken off by hand or by another event are left alone. Timed events, events without
+// a UID and recurrence rules that can't be expanded are skipped. added counts the imported days now recorded.
+func ImportDaysOff(state *data.AppState, events []ics.Event) (added, skipped int) {
+	// A series shares one UID across its master event and overrides, so clear each UID once, up front
+	imported := map[string]bool{}
+	overridden := map[string][]time.Time{}
+	for _, event := range events {
+		if !event.AllDay || event.UID == "" {
+			continue
+		}
+		imported[event.UID] = true
+		if !event.RecurrenceID.IsZero() {
+			overridden[event.UID] = append(overridden[event.UID], event.RecurrenceID)
+		}
+	}
+	kept := []data.DayOff{}
+	for _, off := range state.DaysOff {
+		if !imported[off.UID] {
+			kept = append(kept, off)
+		}
+	}
+	state.DaysOff = kept
+
+	for _, event := range events {
+		if !event.AllDay || event.UID == "" {
+			skipped++
+			continue
+		}
+		starts := []time.Time{event.Start} // An override is a single occurrence, whatever rule it carries
+		if event.RecurrenceID.IsZero() {
+			var err error
+			if starts, err = event.Occurrences(); err != nil {
+				skipped++
+				continue
+			}
+		}
+
+		reason := event.Summary
+		if reason == "" {
+			reason = "calendar"
+		}
+		for _, start := range starts {
+			if event.RecurrenceID.IsZero() && isOverridden(overridden[event.UID], start) {
+				continue
+			}
+			for _, day := range event.At(start).Days() {
+				dateStr := day.Format(data.DateFormat)
+				if dayOffIndex(state, dateStr) >= 0 {
+					continue
+				}
+				state.DaysOff = append(state.DaysOff, data.DayOff{Date: dateStr, Reason: reason, UID: event.UID})
+			}
+		}
+	}
+
+	for _, off := range state.DaysOff {
+		if imported[off.UID] {
+			added++
+		}
+	}
+	sort.SliceStable(state.DaysOff, func(i, j int) bool {
+		return state.DaysOff[i].Date < state.DaysOff[j].Date
+	})
+	RecalculateOverallStats(state)
+	return added, skipped
+}
+
+// isOverridden reports whether an occurrence starting at start was replaced by an override.
+func isOverridden(recurrenceIDs []time.Time, start time.Time) bool {
+	for _, id := range recurrenceIDs {
+		if id.Equal(start) {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveDaysOff clears any days off in [from, to] and returns how many were removed.
+func RemoveDaysOff(state *data.AppState, from, to time.Time) int {
+	first := from.Format(data.DateFormat)
+	last := to.Format(data.DateFormat)
+
+	kept := []data.DayOff{}
+	for _, off := range state.DaysOff {
+		if off.Date < first || off.Date > last {
+			kept = append(kept, off)
+		}
+	}
+	removed := len(state.DaysOff) - len(kept)
+	state.DaysOff = kept
+	RecalculateOverallStats(state)
+	return removed
+}
+
+// GetDayOff returns the day off recorded for a date string, if any.
+func GetDayOff(state *data.AppState, dateStr string) (data.DayOff, bool) {
+	if i := dayOffIndex(state, dateStr); i >= 0 {
+		return state.DaysOff[i], true
+	}
+	return data.DayOff{}, false
+}
+
+// dayOffIndex returns the index of the day off for dateStr, or -1.
+func dayOffIndex(state *data.AppState, dateStr string) int {
+	for i, off := range state.DaysOff {
+		if off.Date == dateStr {
+			return i
+		}
+	}
+	return -1
+}
+
+// WorkingDays counts the non-rest days in the week starting on weekStart (total)
+// and how many of them are not days off (working).
+func WorkingDays(state *data.AppState, weekStart time.Time) (working, total int) {
+	for i := 0; i < 7; i++ {
+		date := weekStart.AddDate(0, 0, i)
+		if timeutil.IsRestDay(date, state.Config) {
+			continue
+		}
+		total++
+		if _, off := GetDayOff(state, date.Format(data.DateFormat)); !off {
+			working++
+		}
+	}
+	return working, total
+}
+
+// proratedGoal scales a weekly goal by the share of working days left after days off.
+func proratedGoal(goal, working, total int) int {
+	if total == 0 || working == total {
+		return goal
+	}
+	// Round half up so a single day off doesn't shave off more than its share
+	return (goal*working*2 + total) / (total * 2)
+}
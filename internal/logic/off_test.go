@@ -0,0 +1,128 @@
+package logic
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"grain/internal/data"
+	"grain/internal/ics"
+)
+
+func newTestState() *data.AppState {
+	return &data.AppState{
+		Config:        data.Config{WeeklyGoal: 90, BreakStart: 12, Timezone: "UTC"},
+		WeeklySurplus: make(map[string]int),
+		Carryover:     make(map[string]int),
+	}
+}
+
+func parseCalendar(t *testing.T, calendar string) []ics.Event {
+	t.Helper()
+	events, err := ics.Parse(strings.NewReader(calendar), time.UTC)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	return events
+}
+
+func daysOff(state *data.AppState) string {
+	dates := []string{}
+	for _, off := range state.DaysOff {
+		dates = append(dates, off.Date)
+	}
+	return strings.Join(dates, " ")
+}
+
+const recurringExam = `BEGIN:VCALENDAR
+BEGIN:VEVENT
+UID:exam
+SUMMARY:Exam
+DTSTART;VALUE=DATE:20261103
+RRULE:FREQ=WEEKLY;COUNT=3
+END:VEVENT
+BEGIN:VEVENT
+UID:exam
+RECURRENCE-ID;VALUE=DATE:20261110
+SUMMARY:Exam (moved)
+DTSTART;VALUE=DATE:20261112
+END:VEVENT
+BEGIN:VEVENT
+UID:trip
+SUMMARY:Trip
+DTSTART;VALUE=DATE:20261120
+DTEND;VALUE=DATE:20261122
+END:VEVENT
+BEGIN:VEVENT
+UID:call
+DTSTART:20261105T090000Z
+END:VEVENT
+BEGIN:VEVENT
+UID:forever
+DTSTART;VALUE=DATE:20261101
+RRULE:FREQ=WEEKLY
+END:VEVENT
+END:VCALENDAR
+`
+
+func TestImportDaysOff(t *testing.T) {
+	tests := []struct {
+		name        string
+		existing    []data.DayOff
+		calendar    string
+		wantDays    string
+		wantAdded   int
+		wantSkipped int
+	}{
+		{
+			name:        "recurring event with a moved occurrence",
+			calendar:    recurringExam,
+			wantDays:    "2026-11-03 2026-11-12 2026-11-17 2026-11-20 2026-11-21",
+			wantAdded:   5,
+			wantSkipped: 2, // The timed call and the rule that never ends
+		},
+		{
+			name:        "hand-entered days are left alone",
+			existing:    []data.DayOff{{Date: "2026-11-17", Reason: "holiday"}},
+			calendar:    recurringExam,
+			wantDays:    "2026-11-03 2026-11-12 2026-11-17 2026-11-20 2026-11-21",
+			wantAdded:   4,
+			wantSkipped: 2,
+		},
+		{
+			name:     "a changed event replaces what it imported before",
+			existing: []data.DayOff{{Date: "2026-10-01", Reason: "Trip", UID: "trip"}},
+			calendar: "BEGIN:VEVENT\nUID:trip\nDTSTART;VALUE=DATE:20261120\nEND:VEVENT\n",
+			wantDays: "2026-11-20", wantAdded: 1,
+		},
+		{
+			name:     "override listed before its master",
+			calendar: "BEGIN:VEVENT\nUID:x\nRECURRENCE-ID;VALUE=DATE:20261104\nDTSTART;VALUE=DATE:20261106\nEND:VEVENT\nBEGIN:VEVENT\nUID:x\nDTSTART;VALUE=DATE:20261103\nRRULE:FREQ=DAILY;COUNT=2\nEND:VEVENT\n",
+			wantDays: "2026-11-03 2026-11-06", wantAdded: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := newTestState()
+			state.DaysOff = append([]data.DayOff{}, tt.existing...)
+			added, skipped := ImportDaysOff(state, parseCalendar(t, tt.calendar))
+			if got := daysOff(state); got != tt.wantDays {
+				t.Errorf("days off = %s, want %s", got, tt.wantDays)
+			}
+			if added != tt.wantAdded || skipped != tt.wantSkipped {
+				t.Errorf("added, skipped = %d, %d, want %d, %d", added, skipped, tt.wantAdded, tt.wantSkipped)
+			}
+		})
+	}
+}
+
+func TestImportDaysOffTwiceChangesNothing(t *testing.T) {
+	state := newTestState()
+	events := parseCalendar(t, recurringExam)
+	ImportDaysOff(state, events)
+	first := daysOff(state)
+	added, _ := ImportDaysOff(state, events)
+	if got := daysOff(state); got != first || added != 5 {
+		t.Errorf("second import: days off = %s (added %d), want %s (added 5)", got, added, first)
+	}
+}
@@ -0,0 +1,240 @@
+package logic
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"grain/internal/data"
+	"grain/internal/ics"
+	"grain/internal/timeutil"
+)
+
+// planGrace is how long after a block ends a study entry still counts as on time.
+// Entries are usually logged when a session finishes, so allow a little slack.
+const planGrace = 30 * time.Minute
+
+// PlanDay compares the study planned for a day with what was logged.
+type PlanDay struct {
+	Date    string
+	Planned int  // Study credits planned
+	Actual  int  // Study credits logged
+	OnTime  int  // Logged credits that fell inside a planned block (and matched its tag, if set)
+	Future  bool // Whether the day is still ahead, so nothing has slipped yet
+}
+
+// Slipped returns the planned credits that weren't studied.
+func (d PlanDay) Slipped() int {
+	if d.Future {
+		return 0
+	}
+	return max(d.Planned-d.Actual, 0)
+}
+
+// PlanReview compares a week's plan with its logs.
+type PlanReview struct {
+	WeekID  string
+	Days    []PlanDay // Days with a plan or logged study, in date order
+	Planned int       // Credits planned on days that have begun
+	Done    int       // Planned credits actually studied on those days
+	OnTime  int       // Credits studied inside their planned blocks
+	Slipped int       // Planned credits not studied
+	Extra   int       // Study beyond the plan, including unplanned days
+	Ahead   int       // Credits still planned for the rest of the week
+}
+
+// Adherence returns the share of planned credits studied so far, as a percentage.
+func (r PlanReview) Adherence() int {
+	if r.Planned == 0 {
+		return 0
+	}
+	return r.Done * 100 / r.Planned
+}
+
+// AddPlanBlock validates and schedules a study block, returning it as stored.
+func AddPlanBlock(state *data.AppState, block data.PlanBlock) (data.PlanBlock, error) {
+	block.Tag = strings.ToLower(strings.TrimSpace(block.Tag))
+	var err error
+	if block.Start, err = normalizeClock(block.Start); err != nil {
+		return block, err
+	}
+	if block.End, err = normalizeClock(block.End); err != nil {
+		return block, err
+	}
+	if block.Credits <= 0 {
+		return block, fmt.Errorf("planned credits must be positive")
+	}
+	start, end, err := BlockTimes(block, state.Config)
+	if err != nil {
+		return block, err
+	}
+	if !end.After(start) {
+		return block, fmt.Errorf("block must end after it starts (%s-%s)", block.Start, block.End)
+	}
+	today := timeutil.LogicalDay(timeutil.Now(state.Config), state.Config)
+	if block.Date < today.Format(data.DateFormat) {
+		return block, fmt.Errorf("cannot plan for %s, it's already in the past", block.Date)
+	}
+	if day, _ := timeutil.ParseDate(block.Date, state.Config); timeutil.IsRestDay(day, state.Config) && restDayPolicy(state.Config) == data.RestDayRefuse {
+		return block, fmt.Errorf("cannot plan for %s, it's a rest day 🧘", block.Date)
+	}
+
+	for _, other := range state.Plan {
+		if other.Date != block.Date {
+			continue
+		}
+		otherStart, otherEnd, err := BlockTimes(other, state.Config)
+		if err == nil && start.Before(otherEnd) && otherStart.Before(end) {
+			return block, fmt.Errorf("block overlaps %s-%s already planned on %s", other.Start, other.End, other.Date)
+		}
+	}
+
+	state.Plan = append(state.Plan, block)
+	sort.SliceStable(state.Plan, func(i, j int) bool {
+		if state.Plan[i].Date != state.Plan[j].Date {
+			return state.Plan[i].Date < state.Plan[j].Date
+		}
+		a, _, _ := BlockTimes(state.Plan[i], state.Config)
+		b, _, _ := BlockTimes(state.Plan[j], state.Config)
+		return a.Before(b)
+	})
+	return block, nil
+}
+
+// RemovePlanBlock deletes the block starting at start (HH:MM) on the given date.
+func RemovePlanBlock(state *data.AppState, date, start string) (data.PlanBlock, error) {
+	start, err := normalizeClock(start)
+	if err != nil {
+		return data.PlanBlock{}, err
+	}
+	for i, block := range state.Plan {
+		if block.Date == date && block.Start == start {
+			state.Plan = append(state.Plan[:i], state.Plan[i+1:]...)
+			return block, nil
+		}
+	}
+	return data.PlanBlock{}, fmt.Errorf("no block starting at %s on %s", start, date)
+}
+
+// normalizeClock rewrites a time of day as zero-padded HH:MM, so "9:00" and "09:00" match.
+func normalizeClock(value string) (string, error) {
+	hour, minute, err := timeutil.ParseClock(value)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%02d:%02d", hour, minute), nil
+}
+
+// BlockTimes returns when a block starts and ends, honoring day_starts_at.
+func BlockTimes(block data.PlanBlock, cfg data.Config) (start, end time.Time, err error) {
+	day, err := timeutil.ParseDate(block.Date, cfg)
+	if err != nil {
+		return start, end, fmt.Errorf("invalid date: '%s'. Use YYYY-MM-DD", block.Date)
+	}
+	if start, err = timeutil.ClockOnDay(day, block.Start, cfg); err != nil {
+		return start, end, err
+	}
+	if end, err = timeutil.ClockOnDay(day, block.End, cfg); err != nil {
+		return start, end, err
+	}
+	return start, end, nil
+}
+
+// UpcomingPlan returns the blocks planned from today onward.
+func UpcomingPlan(state *data.AppState) []data.PlanBlock {
+	today := timeutil.LogicalDay(timeutil.Now(state.Config), state.Config).Format(data.DateFormat)
+	upcoming := []data.PlanBlock{}
+	for _, block := range state.Plan {
+		if block.Date >= today {
+			upcoming = append(upcoming, block)
+		}
+	}
+	return upcoming
+}
+
+// ReviewPlan compares the blocks planned for the week starting on weekStart with the logged study.
+func ReviewPlan(state *data.AppState, weekStart time.Time) PlanReview {
+	review := PlanReview{WeekID: timeutil.GetWeekIDForDate(weekStart, state.Config)}
+	today := timeutil.LogicalDay(timeutil.Now(state.Config), state.Config)
+
+	for i := 0; i < 7; i++ {
+		date := weekStart.AddDate(0, 0, i)
+		day := PlanDay{Date: date.Format(data.DateFormat), Future: date.After(today)}
+
+		blocks := []data.PlanBlock{}
+		for _, block := range state.Plan {
+			if block.Date == day.Date {
+				blocks = append(blocks, block)
+				day.Planned += block.Credits
+			}
+		}
+		if logs, found := timeutil.GetDayLogs(state, day.Date); found {
+			for _, log := range logs.Logs {
+				if log.Type != data.LogTypeStudy {
+					continue
+				}
+				day.Actual += log.Amount
+				if inPlannedBlock(log, blocks, state.Config) {
+					day.OnTime += log.Amount
+				}
+			}
+		}
+		if day.Planned == 0 && day.Actual == 0 {
+			continue
+		}
+		review.Days = append(review.Days, day)
+
+		if day.Future {
+			review.Ahead += day.Planned
+			continue
+		}
+		review.Planned += day.Planned
+		review.Done += min(day.Actual, day.Planned)
+		review.OnTime += min(day.OnTime, day.Planned)
+		review.Slipped += day.Slipped()
+		review.Extra += max(day.Actual-day.Planned, 0)
+	}
+	return review
+}
+
+// inPlannedBlock reports whether a study entry was logged during one of the blocks (or just after it)
+// and matches the block's tag, if it has one.
+func inPlannedBlock(log data.Log, blocks []data.PlanBlock, cfg data.Config) bool {
+	for _, block := range blocks {
+		if block.Tag != "" && block.Tag != log.Tag {
+			continue
+		}
+		start, end, err := BlockTimes(block, cfg)
+		if err != nil {
+			continue
+		}
+		if !log.Timestamp.Before(start) && !log.Timestamp.After(end.Add(planGrace)) {
+			return true
+		}
+	}
+	return false
+}
+
+// PlanEvents converts planned blocks into calendar events for export.
+func PlanEvents(state *data.AppState, blocks []data.PlanBlock) []ics.Event {
+	events := []ics.Event{}
+	for _, block := range blocks {
+		start, end, err := BlockTimes(block, state.Config)
+		if err != nil {
+			continue
+		}
+		summary := fmt.Sprintf("Study: %d credits", block.Credits)
+		if block.Tag != "" {
+			summary += fmt.Sprintf(" #%s", block.Tag)
+		}
+		events = append(events, ics.Event{
+			// Blocks never overlap, so the date and start time identify one; re-exports update it in place
+			UID:     fmt.Sprintf("%s-%s@grain", strings.ReplaceAll(block.Date, "-", ""), strings.ReplaceAll(block.Start, ":", "")),
+			Summary: summary,
+			Start:   start,
+			End:     end,
+		})
+	}
+	return events
+}
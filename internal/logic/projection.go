@@ -0,0 +1,154 @@
+package logic
+
+import (
+	"time"
+
+	"grain/internal/data"
+	"grain/internal/timeutil"
+)
+
+// historyWeeks is how many completed weeks feed the weekday averages used for projections.
+const historyWeeks = 8
+
+// Projection forecasts how the current week ends if the pace so far holds.
+type Projection struct {
+	ElapsedDays      int  // Working days before today
+	RemainingDays    int  // Working days left, including today
+	ProjectedStudy   int  // Expected study credits at the end of the week
+	RequiredPerDay   int  // Credits per remaining working day needed to reach the goal (0 once reached)
+	ProjectedSurplus int  // Expected study credits above the goal
+	ProjectedBreaks  int  // Break credits the rules engine would award at the projected total
+	OnTrack          bool // Whether the projection reaches the goal
+}
+
+// ProjectWeek projects the end of the current week from this week's pace and
+// the average study on each weekday over recent weeks.
+func ProjectWeek(state *data.AppState) Projection {
+	now := timeutil.Now(state.Config)
+	summary := CurrentWeekSummary(state)
+	today := timeutil.LogicalDay(now, state.Config)
+	studyOn := dailyGoalStudy(state) // Matches summary.Study, which leaves out study that repaid a loan
+	averages := weekdayAverages(state, summary.Start, studyOn)
+
+	var projection Projection
+	studyBeforeToday := 0
+	expected := 0.0
+	remaining := []time.Time{}
+	for date := summary.Start; !date.After(summary.End); date = date.AddDate(0, 0, 1) {
+		if !isWorkingDay(state, date) {
+			continue
+		}
+		if date.Before(today) {
+			projection.ElapsedDays++
+			studyBeforeToday += studyOn[date.Format(data.DateFormat)]
+		} else {
+			remaining = append(remaining, date)
+		}
+	}
+	projection.RemainingDays = len(remaining)
+
+	// Blend this week's pace with each weekday's history; use whichever exists if only one does
+	for _, date := range remaining {
+		pace, havePace := 0.0, projection.ElapsedDays > 0
+		if havePace {
+			pace = float64(studyBeforeToday) / float64(projection.ElapsedDays)
+		}
+		average, haveAverage := averages[date.Weekday()]
+
+		dayExpected := 0.0
+		switch {
+		case havePace && haveAverage:
+			dayExpected = (pace + average) / 2
+		case havePace:
+			dayExpected = pace
+		case haveAverage:
+			dayExpected = average
+		}
+		if date.Equal(today) {
+			// Today's logs are already in the total, so only the rest of the day is projected
+			dayExpected -= float64(studyOn[date.Format(data.DateFormat)])
+			if dayExpected < 0 {
+				dayExpected = 0
+			}
+		}
+		expected += dayExpected
+	}
+
+	projection.ProjectedStudy = summary.Study + int(expected+0.5)
+	if short := summary.Goal - summary.Study; short > 0 && projection.RemainingDays > 0 {
+		projection.RequiredPerDay = (short + projection.RemainingDays - 1) / projection.RemainingDays
+	}
+	if projection.ProjectedStudy > summary.Goal {
+		projection.ProjectedSurplus = projection.ProjectedStudy - summary.Goal
+	}
+	projection.ProjectedBreaks = EarnedBreaks(projection.ProjectedStudy, summary.Goal, state.Config)
+	projection.OnTrack = projection.ProjectedStudy >= summary.Goal
+	return projection
+}
+
+// weekdayAverages returns the average study per working weekday over the completed weeks
+// before currentStart, starting no earlier than the first logged week.
+func weekdayAverages(state *data.AppState, currentStart time.Time, studyOn map[string]int) map[time.Weekday]float64 {
+	firstWeek, ok := firstLoggedWeek(state)
+	if !ok {
+		return map[time.Weekday]float64{}
+	}
+
+	totals := map[time.Weekday]int{}
+	counts := map[time.Weekday]int{}
+	for i := 1; i <= historyWeeks; i++ {
+		weekStart := currentStart.AddDate(0, 0, -7*i)
+		if weekStart.Before(firstWeek) {
+			break
+		}
+		for date := weekStart; date.Before(weekStart.AddDate(0, 0, 7)); date = date.AddDate(0, 0, 1) {
+			if !isWorkingDay(state, date) {
+				continue
+			}
+			totals[date.Weekday()] += studyOn[date.Format(data.DateFormat)]
+			counts[date.Weekday()]++
+		}
+	}
+
+	averages := map[time.Weekday]float64{}
+	for weekday, count := range counts {
+		averages[weekday] = float64(totals[weekday]) / float64(count)
+	}
+	return averages
+}
+
+// isWorkingDay reports whether a date is neither a rest day nor a day off.
+func isWorkingDay(state *data.AppState, date time.Time) bool {
+	if timeutil.IsRestDay(date, state.Config) {
+		return false
+	}
+	_, off := GetDayOff(state, date.Format(data.DateFormat))
+	return !off
+}
+
+// dailyStudy returns the study credits logged on each date, keyed by "YYYY-MM-DD".
+func dailyStudy(state *data.AppState) map[string]int {
+	studyOn := map[string]int{}
+	for _, day := range state.Logs {
+		for _, log := range day.Logs {
+			if log.Type == data.LogTypeStudy {
+				studyOn[day.Date] += log.Amount
+			}
+		}
+	}
+	return studyOn
+}
+
+// dailyGoalStudy returns the study credits counted towards the goal on each date: like dailyStudy,
+// but without the credits that went to repaying a break loan.
+func dailyGoalStudy(state *data.AppState) map[string]int {
+	studyOn := map[string]int{}
+	for _, day := range state.Logs {
+		for _, log := range day.Logs {
+			if log.Type == data.LogTypeStudy {
+				studyOn[day.Date] += log.Amount - log.Repaid
+			}
+		}
+	}
+	return studyOn
+}
@@ -0,0 +1,42 @@
+package logic
+
+import (
+	"testing"
+	"time"
+
+	"grain/internal/data"
+	"grain/internal/timeutil"
+)
+
+func TestProjectWeekLeavesOutRepaidStudy(t *testing.T) {
+	state := newTestState()
+	state.Config.RestDays = []string{}
+	today := timeutil.LogicalDay(timeutil.Now(state.Config), state.Config)
+	// Start the week two days ago, so there are two elapsed days and five left including today
+	weekStart := today.AddDate(0, 0, -2)
+	state.Config.WeekStart = weekStart.Weekday().String()
+
+	tests := []struct {
+		name          string
+		repaid        int
+		wantProjected int
+		wantRequired  int
+	}{
+		{name: "no loan", repaid: 0, wantProjected: 10 + 5*5, wantRequired: 16},
+		{name: "part of the study repaid a loan", repaid: 4, wantProjected: 6 + 5*3, wantRequired: 17},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state.Logs = []data.Day{{Date: weekStart.Format(data.DateFormat), Logs: []data.Log{
+				{Type: data.LogTypeStudy, Timestamp: weekStart.Add(10 * time.Hour), Amount: 10, Repaid: tt.repaid},
+			}}}
+			projection := ProjectWeek(state)
+			if projection.ElapsedDays != 2 || projection.RemainingDays != 5 {
+				t.Fatalf("elapsed, remaining = %d, %d, want 2, 5", projection.ElapsedDays, projection.RemainingDays)
+			}
+			if projection.ProjectedStudy != tt.wantProjected || projection.RequiredPerDay != tt.wantRequired {
+				t.Errorf("projected %d, required %d/day, want %d and %d/day", projection.ProjectedStudy, projection.RequiredPerDay, tt.wantProjected, tt.wantRequired)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package logic
+
+import (
+	"grain/internal/data"
+	"grain/internal/timeutil"
+)
+
+// Milestones are the lifetime study credit totals worth celebrating.
+var Milestones = []int{100, 250, 500, 1000, 2500, 5000, 10000}
+
+// Record is a personal best and when it was set. A tie never replaces the earlier record.
+type Record struct {
+	Value int
+	Date  string // Day it was set ("YYYY-MM-DD"); for weekly records, the week's first day
+	Week  string // Week ID ("YYYY-WW") for weekly records
+}
+
+// Milestone is a lifetime study total and the day it was reached.
+type Milestone struct {
+	Credits int
+	Date    string // Empty while not yet reached
+}
+
+// Records holds every personal record, recomputed from the logs so undone entries never linger.
+type Records struct {
+	BestDay        Record            // Most study credits in one day
+	BestWeek       Record            // Most study credits counted towards one week's goal
+	BestSurplus    Record            // Most break credits earned in one week
+	LongestSession Record            // Largest single study entry
+	LongestStreak  data.StreakRecord // Longest run of weeks meeting the goal
+	LongestDaily   data.StreakRecord // Longest run of show-up days
+	TotalStudy     int
+	Milestones     []Milestone // Every milestone, reached ones dated
+}
+
+// NextMilestone returns the first milestone not reached yet.
+func (r Records) NextMilestone() (Milestone, bool) {
+	for _, milestone := range r.Milestones {
+		if milestone.Date == "" {
+			return milestone, true
+		}
+	}
+	return Milestone{}, false
+}
+
+// ComputeRecords recomputes every personal record from the logs.
+func ComputeRecords(state *data.AppState) Records {
+	records := Records{}
+	for _, credits := range Milestones {
+		records.Milestones = append(records.Milestones, Milestone{Credits: credits})
+	}
+
+	// Days and entries, in order, so milestones get the day they were crossed
+	for _, day := range state.Logs {
+		dayStudy := 0
+		for _, log := range day.Logs {
+			if log.Type != data.LogTypeStudy {
+				continue
+			}
+			dayStudy += log.Amount
+			records.LongestSession.beat(log.Amount, day.Date, "")
+
+			before := records.TotalStudy
+			records.TotalStudy += log.Amount
+			for i, milestone := range records.Milestones {
+				if before < milestone.Credits && records.TotalStudy >= milestone.Credits {
+					records.Milestones[i].Date = day.Date
+				}
+			}
+		}
+		records.BestDay.beat(dayStudy, day.Date, "")
+	}
+
+	records.BestWeek, records.BestSurplus = bestWeeks(state)
+	_, records.LongestStreak = WeeklyStreaks(state)
+	_, records.LongestDaily = DailyStreaks(state)
+	return records
+}
+
+// bestWeeks finds the weeks with the most study and the most breaks earned, the current week included.
+// They use the same summaries as every weekly view.
+func bestWeeks(state *data.AppState) (study, surplus Record) {
+	firstWeek, ok := firstLoggedWeek(state)
+	if !ok {
+		return study, surplus
+	}
+	currentStart, _ := timeutil.GetWeekBounds(timeutil.Now(state.Config), state.Config)
+	for start := firstWeek; !start.After(currentStart); start = start.AddDate(0, 0, 7) {
+		summary := SummarizeWeek(state, start)
+		study.beat(summary.Study, start.Format(data.DateFormat), summary.ID)
+		surplus.beat(summary.Earned, start.Format(data.DateFormat), summary.ID)
+	}
+	return study, surplus
+}
+
+// beat replaces the record if value is strictly higher.
+func (r *Record) beat(value int, date, week string) {
+	if value > r.Value {
+		*r = Record{Value: value, Date: date, Week: week}
+	}
+}
@@ -0,0 +1,56 @@
+package logic
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"grain/internal/data"
+)
+
+// ReflectionTemplate returns the Markdown a new reflection starts from, headed by the week's numbers.
+func ReflectionTemplate(summary WeekSummary) string {
+	lines := []string{
+		fmt.Sprintf("# Week %s (%s – %s)", summary.ID, summary.Start.Format("Jan 2"), summary.End.Format("Jan 2")),
+		"",
+		fmt.Sprintf("Study %d / %d · Breaks used %d · Surplus %d", summary.Study, summary.Goal, summary.BreaksUsed, summary.Earned),
+		"",
+		"## What worked",
+		"",
+		"",
+		"## What to adjust",
+		"",
+		"",
+		"## Focus for next week",
+		"",
+		"",
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SaveReflection stores the reflection for a week. Text left as the untouched template is ignored,
+// and an emptied file deletes the reflection. It reports whether anything changed.
+func SaveReflection(state *data.AppState, weekID, text, template string, now time.Time) bool {
+	text = strings.TrimSpace(text)
+	existing, found := state.Reflections[weekID]
+	switch {
+	case text == "":
+		if !found {
+			return false
+		}
+		delete(state.Reflections, weekID)
+		return true
+	case text == strings.TrimSpace(template) && !found:
+		return false
+	case found && text == existing.Text:
+		return false
+	}
+	state.Reflections[weekID] = data.Reflection{Text: text, Updated: now}
+	return true
+}
+
+// HasReflection reports whether a reflection was written for the week.
+func HasReflection(state *data.AppState, weekID string) bool {
+	_, found := state.Reflections[weekID]
+	return found
+}
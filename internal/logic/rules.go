@@ -0,0 +1,186 @@
+package logic
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"grain/internal/data"
+	"grain/internal/timeutil"
+)
+
+// breakRules returns the configured break rules, or the classic "2 per surplus credit" rule.
+func breakRules(cfg data.Config) data.BreakRules {
+	if cfg.BreakRules == nil {
+		return data.BreakRules{SurplusMultiplier: data.DefaultSurplusMultiplier}
+	}
+	return *cfg.BreakRules
+}
+
+// restDayPolicy returns the configured rest-day policy, defaulting to refusing rest-day logs.
+func restDayPolicy(cfg data.Config) string {
+	if cfg.RestDayPolicy == "" {
+		return data.RestDayRefuse
+	}
+	return cfg.RestDayPolicy
+}
+
+// EarnedBreaks is the single rules engine for break credits earned in a week.
+// It combines the surplus multiplier, earn-as-you-go ratio and tier bonuses, then applies the weekly cap.
+func EarnedBreaks(study, goal int, cfg data.Config) int {
+	rules := breakRules(cfg)
+	earned := 0
+
+	// Surplus: every credit above the goal earns SurplusMultiplier breaks
+	if study > goal && rules.SurplusMultiplier > 0 {
+		earned += int(math.Floor(float64(study-goal) * rules.SurplusMultiplier))
+	}
+
+	// Earn as you go: EarnBreaks for every EarnEvery study credits, whether or not the goal is met
+	if rules.EarnEvery > 0 {
+		perChunk := rules.EarnBreaks
+		if perChunk <= 0 {
+			perChunk = 1
+		}
+		earned += (study / rules.EarnEvery) * perChunk
+	}
+
+	// Tiers: one-off bonuses once the week's study reaches each threshold
+	for _, tier := range rules.Tiers {
+		if tier.Study > 0 && study >= tier.Study {
+			earned += tier.Bonus
+		}
+	}
+
+	if rules.MaxPerWeek > 0 && earned > rules.MaxPerWeek {
+		earned = rules.MaxPerWeek
+	}
+	if earned < 0 {
+		earned = 0
+	}
+	return earned
+}
+
+// CarriedOver applies the rollover policy to the break credits left unused at the end of a week.
+func CarriedOver(unused int, cfg data.Config) int {
+	if unused <= 0 {
+		return 0
+	}
+	switch cfg.Rollover.Mode {
+	case data.RolloverFull:
+		return unused
+	case data.RolloverCapped:
+		if unused > cfg.Rollover.Cap {
+			return cfg.Rollover.Cap
+		}
+		return unused
+	case data.RolloverDecay:
+		return unused * (100 - cfg.Rollover.DecayPercent) / 100
+	default:
+		return 0
+	}
+}
+
+// GoalDebt returns how many credits of last week's shortfall are added to this week's goal.
+func GoalDebt(shortfall int, cfg data.Config) int {
+	if !cfg.Debt.Enabled || shortfall <= 0 {
+		return 0
+	}
+	percent := cfg.Debt.Percent
+	if percent <= 0 {
+		percent = 100
+	}
+	debt := shortfall * percent / 100
+	if cfg.Debt.Cap > 0 && debt > cfg.Debt.Cap {
+		debt = cfg.Debt.Cap
+	}
+	return debt
+}
+
+// GoalsFor returns the weekly goal and break start in force for the week starting on weekStart.
+// Effective-dated changes in GoalHistory apply from their week onward, and WeekGoals overrides a single week.
+func GoalsFor(cfg data.Config, weekStart time.Time) (goal, breakStart int) {
+	goal, breakStart = ScheduledGoals(cfg, weekStart)
+	if override, ok := cfg.WeekGoals[timeutil.GetWeekIDForDate(weekStart, cfg)]; ok && override > 0 {
+		goal = override
+	}
+	return goal, breakStart
+}
+
+// ScheduledGoals returns the goal and break start from the goal history, ignoring per-week overrides.
+func ScheduledGoals(cfg data.Config, weekStart time.Time) (goal, breakStart int) {
+	goal, breakStart = cfg.WeeklyGoal, cfg.BreakStart
+	from := weekStart.Format(data.DateFormat)
+	for _, change := range cfg.GoalHistory {
+		if change.From <= from {
+			goal, breakStart = change.WeeklyGoal, change.BreakStart
+		}
+	}
+	return goal, breakStart
+}
+
+// SetGoals records a goal and break-start change taking effect from the week starting on from,
+// leaving weeks before it judged by the goal that applied at the time.
+func SetGoals(cfg *data.Config, from time.Time, goal, breakStart int) {
+	fromStr := from.Format(data.DateFormat)
+
+	// Seed the history with the original values so earlier weeks keep them
+	if len(cfg.GoalHistory) == 0 {
+		cfg.GoalHistory = []data.GoalChange{{WeeklyGoal: cfg.WeeklyGoal, BreakStart: cfg.BreakStart}}
+	}
+
+	history := []data.GoalChange{}
+	for _, change := range cfg.GoalHistory {
+		if change.From != fromStr {
+			history = append(history, change)
+		}
+	}
+	history = append(history, data.GoalChange{From: fromStr, WeeklyGoal: goal, BreakStart: breakStart})
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].From < history[j].From
+	})
+	cfg.GoalHistory = history
+
+	// Keep the top-level values pointing at the latest change, so hand edits to them can be detected
+	latest := history[len(history)-1]
+	cfg.WeeklyGoal, cfg.BreakStart = latest.WeeklyGoal, latest.BreakStart
+}
+
+// ReconcileGoals folds hand edits of the top-level weekly_goal and break_start into the goal history,
+// which would otherwise override them. An edit updates the latest change if it hasn't started yet,
+// and otherwise applies from the current week. It returns the week the edit applies from, if there was one.
+func ReconcileGoals(cfg *data.Config) (fromWeek string, edited bool) {
+	if len(cfg.GoalHistory) == 0 {
+		return "", false // Without a history the top-level values are used as they are
+	}
+	latest := &cfg.GoalHistory[len(cfg.GoalHistory)-1]
+	if latest.WeeklyGoal == cfg.WeeklyGoal && latest.BreakStart == cfg.BreakStart {
+		return "", false
+	}
+
+	currentStart, _ := timeutil.GetWeekBounds(timeutil.Now(*cfg), *cfg)
+	if latest.From > currentStart.Format(data.DateFormat) {
+		if goal, breakStart := ScheduledGoals(*cfg, currentStart); goal == cfg.WeeklyGoal && breakStart == cfg.BreakStart {
+			// Older configs kept the top-level values at this week's goals while a change was upcoming
+			cfg.WeeklyGoal, cfg.BreakStart = latest.WeeklyGoal, latest.BreakStart
+			return "", false
+		}
+		latest.WeeklyGoal, latest.BreakStart = cfg.WeeklyGoal, cfg.BreakStart
+		from, _ := timeutil.ParseDate(latest.From, *cfg) // Validated when the config was loaded
+		return timeutil.GetWeekIDForDate(from, *cfg), true
+	}
+	SetGoals(cfg, currentStart, cfg.WeeklyGoal, cfg.BreakStart)
+	return timeutil.GetWeekIDForDate(currentStart, *cfg), true
+}
+
+// SetWeekGoal sets the goal for a single week; a goal of 0 removes the override.
+func SetWeekGoal(cfg *data.Config, weekID string, goal int) {
+	if goal <= 0 {
+		delete(cfg.WeekGoals, weekID)
+		return
+	}
+	if cfg.WeekGoals == nil {
+		cfg.WeekGoals = make(map[string]int)
+	}
+	cfg.WeekGoals[weekID] = goal
+}
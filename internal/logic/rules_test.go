@@ -0,0 +1,307 @@
+package logic
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"grain/internal/data"
+	"grain/internal/timeutil"
+)
+
+func TestEarnedBreaks(t *testing.T) {
+	tests := []struct {
+		name  string
+		study int
+		goal  int
+		rules *data.BreakRules
+		want  int
+	}{
+		{name: "default rules below goal", study: 80, goal: 90, want: 0},
+		{name: "default rules at goal", study: 90, goal: 90, want: 0},
+		{name: "default rules above goal", study: 95, goal: 90, want: 10},
+		{name: "fractional multiplier rounds down", study: 95, goal: 90, rules: &data.BreakRules{SurplusMultiplier: 1.5}, want: 7},
+		{name: "zero multiplier disables surplus", study: 95, goal: 90, rules: &data.BreakRules{}, want: 0},
+		{name: "earn as you go below goal", study: 23, goal: 90, rules: &data.BreakRules{EarnEvery: 5, EarnBreaks: 2}, want: 8},
+		{name: "earn as you go defaults to one break", study: 23, goal: 90, rules: &data.BreakRules{EarnEvery: 5}, want: 4},
+		{name: "surplus and earn as you go add up", study: 100, goal: 90, rules: &data.BreakRules{SurplusMultiplier: 2, EarnEvery: 10, EarnBreaks: 1}, want: 30},
+		{
+			name: "tiers reached so far", study: 100, goal: 90,
+			rules: &data.BreakRules{Tiers: []data.BreakTier{{Study: 50, Bonus: 1}, {Study: 100, Bonus: 3}, {Study: 120, Bonus: 5}}},
+			want:  4,
+		},
+		{name: "weekly cap", study: 120, goal: 90, rules: &data.BreakRules{SurplusMultiplier: 2, MaxPerWeek: 30}, want: 30},
+		{name: "no study", study: 0, goal: 90, rules: &data.BreakRules{SurplusMultiplier: 2, EarnEvery: 5}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := data.Config{BreakRules: tt.rules}
+			if got := EarnedBreaks(tt.study, tt.goal, cfg); got != tt.want {
+				t.Errorf("EarnedBreaks(%d, %d) = %d, want %d", tt.study, tt.goal, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEarnedBreaksFromConfigJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   int
+	}{
+		{name: "partial block keeps the default multiplier", config: `{"break_rules": {"earn_every": 5}}`, want: 10 + 19},
+		{name: "explicit zero multiplier", config: `{"break_rules": {"surplus_multiplier": 0, "earn_every": 5}}`, want: 19},
+		{name: "no block", config: `{}`, want: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg data.Config
+			if err := json.Unmarshal([]byte(tt.config), &cfg); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if got := EarnedBreaks(95, 90, cfg); got != tt.want {
+				t.Errorf("EarnedBreaks(95, 90) = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGoalDebt(t *testing.T) {
+	tests := []struct {
+		name      string
+		shortfall int
+		debt      data.Debt
+		want      int
+	}{
+		{name: "disabled", shortfall: 30, debt: data.Debt{Percent: 100}, want: 0},
+		{name: "goal met", shortfall: -5, debt: data.Debt{Enabled: true, Percent: 100}, want: 0},
+		{name: "full shortfall", shortfall: 30, debt: data.Debt{Enabled: true, Percent: 100}, want: 30},
+		{name: "share rounds down", shortfall: 25, debt: data.Debt{Enabled: true, Percent: 50}, want: 12},
+		{name: "unset percent means all of it", shortfall: 30, debt: data.Debt{Enabled: true}, want: 30},
+		{name: "capped", shortfall: 30, debt: data.Debt{Enabled: true, Percent: 100, Cap: 20}, want: 20},
+		{name: "under the cap", shortfall: 10, debt: data.Debt{Enabled: true, Percent: 100, Cap: 20}, want: 10},
+		{name: "cap 0 means no cap", shortfall: 300, debt: data.Debt{Enabled: true, Percent: 100, Cap: 0}, want: 300},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GoalDebt(tt.shortfall, data.Config{Debt: tt.debt}); got != tt.want {
+				t.Errorf("GoalDebt(%d) = %d, want %d", tt.shortfall, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDebtAcrossWeeks(t *testing.T) {
+	// Study per week from the week of Aug 31 on, against a goal of 90; the first logged week owes nothing
+	study := map[string]int{"2026-08-31": 60, "2026-09-07": 100, "2026-09-14": 50}
+	weeks := []string{"2026-08-31", "2026-09-07", "2026-09-14", "2026-09-21"}
+	tests := []struct {
+		name      string
+		debt      data.Debt
+		wantGoals []int
+	}{
+		{name: "off", debt: data.Debt{Percent: 100}, wantGoals: []int{90, 90, 90, 90}},
+		// 60 of 90 leaves 30 owed; 100 misses the 120 but meets the base goal, so nothing compounds; 50 leaves 40 owed
+		{name: "full", debt: data.Debt{Enabled: true, Percent: 100}, wantGoals: []int{90, 120, 90, 130}},
+		{name: "half", debt: data.Debt{Enabled: true, Percent: 50}, wantGoals: []int{90, 105, 90, 110}},
+		{name: "capped", debt: data.Debt{Enabled: true, Percent: 100, Cap: 10}, wantGoals: []int{90, 100, 90, 100}},
+		{name: "uncapped", debt: data.Debt{Enabled: true, Percent: 100, Cap: 0}, wantGoals: []int{90, 120, 90, 130}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := newTestState()
+			state.Config.Debt = tt.debt
+			state.Config.RestDays = []string{} // Keep every day a working day
+			for _, week := range weeks {
+				if amount, ok := study[week]; ok {
+					state.Logs = append(state.Logs, data.Day{Date: week, Logs: []data.Log{
+						{Type: data.LogTypeStudy, Timestamp: at(t, week, 10), Amount: amount},
+					}})
+				}
+			}
+			for i, week := range weeks {
+				summary := weekOf(t, state, week)
+				if summary.Goal != tt.wantGoals[i] || summary.Goal != summary.BaseGoal+summary.Debt {
+					t.Errorf("week of %s: goal %d (base %d + debt %d), want %d", week, summary.Goal, summary.BaseGoal, summary.Debt, tt.wantGoals[i])
+				}
+			}
+		})
+	}
+}
+
+// goalHistory summarizes a goal history as "from:goal/breaks" entries.
+func goalHistory(cfg data.Config) string {
+	entries := []string{}
+	for _, change := range cfg.GoalHistory {
+		entries = append(entries, fmt.Sprintf("%s:%d/%d", change.From, change.WeeklyGoal, change.BreakStart))
+	}
+	return strings.Join(entries, " ")
+}
+
+func TestGoalsFor(t *testing.T) {
+	cfg := data.Config{
+		WeeklyGoal: 100, BreakStart: 14, Timezone: "UTC",
+		GoalHistory: []data.GoalChange{
+			{WeeklyGoal: 80, BreakStart: 10},
+			{From: "2026-09-07", WeeklyGoal: 90, BreakStart: 12},
+			{From: "2026-09-21", WeeklyGoal: 100, BreakStart: 14},
+		},
+		WeekGoals: map[string]int{"2026-38": 50},
+	}
+	tests := []struct {
+		week                       string
+		wantGoal, wantBreaks       int
+		wantScheduled, wantOverall int
+	}{
+		{week: "2026-08-31", wantGoal: 80, wantBreaks: 10, wantScheduled: 80},
+		{week: "2026-09-07", wantGoal: 90, wantBreaks: 12, wantScheduled: 90},
+		{week: "2026-09-14", wantGoal: 50, wantBreaks: 12, wantScheduled: 90}, // Overridden for this week only
+		{week: "2026-09-21", wantGoal: 100, wantBreaks: 14, wantScheduled: 100},
+		{week: "2027-01-04", wantGoal: 100, wantBreaks: 14, wantScheduled: 100},
+	}
+	for _, tt := range tests {
+		start, _ := timeutil.ParseDate(tt.week, cfg)
+		if goal, breaks := GoalsFor(cfg, start); goal != tt.wantGoal || breaks != tt.wantBreaks {
+			t.Errorf("GoalsFor(%s) = %d, %d, want %d, %d", tt.week, goal, breaks, tt.wantGoal, tt.wantBreaks)
+		}
+		if goal, _ := ScheduledGoals(cfg, start); goal != tt.wantScheduled {
+			t.Errorf("ScheduledGoals(%s) = %d, want %d", tt.week, goal, tt.wantScheduled)
+		}
+	}
+}
+
+func TestSetGoals(t *testing.T) {
+	tests := []struct {
+		name        string
+		history     []data.GoalChange
+		from        string
+		goal        int
+		wantHistory string
+		wantGoal    int // Top-level goal afterwards
+	}{
+		{name: "first change seeds the history", from: "2026-09-07", goal: 90, wantHistory: ":80/10 2026-09-07:90/10", wantGoal: 90},
+		{
+			name:        "same week replaces the change",
+			history:     []data.GoalChange{{WeeklyGoal: 80, BreakStart: 10}, {From: "2026-09-07", WeeklyGoal: 90, BreakStart: 10}},
+			from:        "2026-09-07",
+			goal:        95,
+			wantHistory: ":80/10 2026-09-07:95/10",
+			wantGoal:    95,
+		},
+		{
+			name:        "earlier change goes in date order",
+			history:     []data.GoalChange{{WeeklyGoal: 80, BreakStart: 10}, {From: "2026-09-21", WeeklyGoal: 100, BreakStart: 10}},
+			from:        "2026-09-07",
+			goal:        90,
+			wantHistory: ":80/10 2026-09-07:90/10 2026-09-21:100/10",
+			wantGoal:    100, // The latest change is still the top-level goal
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := data.Config{WeeklyGoal: 80, BreakStart: 10, Timezone: "UTC", GoalHistory: tt.history}
+			if len(tt.history) > 0 {
+				latest := tt.history[len(tt.history)-1]
+				cfg.WeeklyGoal = latest.WeeklyGoal
+			}
+			from, _ := timeutil.ParseDate(tt.from, cfg)
+			SetGoals(&cfg, from, tt.goal, 10)
+			if got := goalHistory(cfg); got != tt.wantHistory {
+				t.Errorf("history = %s, want %s", got, tt.wantHistory)
+			}
+			if cfg.WeeklyGoal != tt.wantGoal {
+				t.Errorf("weekly_goal = %d, want %d", cfg.WeeklyGoal, tt.wantGoal)
+			}
+		})
+	}
+}
+
+func TestReconcileGoals(t *testing.T) {
+	cfg := data.Config{Timezone: "UTC"}
+	currentStart, _ := timeutil.GetWeekBounds(timeutil.Now(cfg), cfg)
+	thisWeek := currentStart.Format(data.DateFormat)
+	twoWeeksAgo := currentStart.AddDate(0, 0, -14).Format(data.DateFormat)
+	nextWeek := currentStart.AddDate(0, 0, 7).Format(data.DateFormat)
+	change := func(from string, goal int) data.GoalChange {
+		return data.GoalChange{From: from, WeeklyGoal: goal, BreakStart: 12}
+	}
+
+	tests := []struct {
+		name         string
+		goal         int // Top-level weekly_goal as found in config.json
+		history      []data.GoalChange
+		wantEdited   bool
+		wantFrom     string
+		wantHistory  []data.GoalChange
+		wantTopLevel int
+	}{
+		{name: "no history", goal: 95, wantTopLevel: 95},
+		{
+			name:         "not edited",
+			goal:         90,
+			history:      []data.GoalChange{change("", 80), change(twoWeeksAgo, 90)},
+			wantHistory:  []data.GoalChange{change("", 80), change(twoWeeksAgo, 90)},
+			wantTopLevel: 90,
+		},
+		{
+			name:         "edit applies from this week",
+			goal:         100,
+			history:      []data.GoalChange{change("", 80), change(twoWeeksAgo, 90)},
+			wantEdited:   true,
+			wantFrom:     thisWeek,
+			wantHistory:  []data.GoalChange{change("", 80), change(twoWeeksAgo, 90), change(thisWeek, 100)},
+			wantTopLevel: 100,
+		},
+		{
+			name:         "edit replaces this week's change",
+			goal:         100,
+			history:      []data.GoalChange{change("", 80), change(thisWeek, 90)},
+			wantEdited:   true,
+			wantFrom:     thisWeek,
+			wantHistory:  []data.GoalChange{change("", 80), change(thisWeek, 100)},
+			wantTopLevel: 100,
+		},
+		{
+			name:         "edit updates an upcoming change",
+			goal:         110,
+			history:      []data.GoalChange{change("", 90), change(nextWeek, 100)},
+			wantEdited:   true,
+			wantFrom:     nextWeek,
+			wantHistory:  []data.GoalChange{change("", 90), change(nextWeek, 110)},
+			wantTopLevel: 110,
+		},
+		{
+			name:         "older config with an upcoming change isn't an edit",
+			goal:         90, // This week's goal, as older versions kept it
+			history:      []data.GoalChange{change("", 90), change(nextWeek, 100)},
+			wantHistory:  []data.GoalChange{change("", 90), change(nextWeek, 100)},
+			wantTopLevel: 100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := data.Config{WeeklyGoal: tt.goal, BreakStart: 12, Timezone: "UTC", GoalHistory: append([]data.GoalChange{}, tt.history...)}
+			fromWeek, edited := ReconcileGoals(&cfg)
+			wantFromWeek := ""
+			if tt.wantFrom != "" {
+				from, _ := timeutil.ParseDate(tt.wantFrom, cfg)
+				wantFromWeek = timeutil.GetWeekIDForDate(from, cfg)
+			}
+			if edited != tt.wantEdited || fromWeek != wantFromWeek {
+				t.Errorf("edited, from = %v, %q, want %v, %q", edited, fromWeek, tt.wantEdited, wantFromWeek)
+			}
+			if got, want := goalHistory(cfg), goalHistory(data.Config{GoalHistory: tt.wantHistory}); got != want {
+				t.Errorf("history = %s, want %s", got, want)
+			}
+			if cfg.WeeklyGoal != tt.wantTopLevel {
+				t.Errorf("weekly_goal = %d, want %d", cfg.WeeklyGoal, tt.wantTopLevel)
+			}
+
+			// Reconciling again finds nothing more to do
+			if _, edited := ReconcileGoals(&cfg); edited {
+				t.Errorf("second ReconcileGoals reported another edit")
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package logic
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"grain/internal/data"
+	"grain/internal/timeutil"
+)
+
+// SimChanges lists the settings a simulation replaces; nil fields keep the current value.
+type SimChanges struct {
+	WeeklyGoal        *int
+	BreakStart        *int
+	SurplusMultiplier *float64
+	Rollover          *data.Rollover
+	RestDays          []string // nil keeps the configured rest days; empty means none
+}
+
+// SimResult is what a set of rules produced over the replayed weeks.
+type SimResult struct {
+	Weeks         int               // Completed weeks replayed
+	GoalsMet      int               // Weeks that met their goal
+	Study         int               // Study credits counted towards goals
+	Surplus       int               // Study credits above the goals
+	BreaksEarned  int               // Break credits earned under the break rules
+	BreaksGranted int               // Break credits granted at the start of weeks
+	BreaksUsed    int               // Break credits spent
+	BreaksLost    int               // Unused break credits that didn't roll over
+	Streak        data.StreakRecord // Streak at the end of the replay
+	Longest       data.StreakRecord // Longest streak during the replay
+}
+
+// SimulatedConfig returns cfg with the changes applied. A new goal or break start replaces
+// the whole goal history, as if it had always applied.
+func SimulatedConfig(cfg data.Config, changes SimChanges) data.Config {
+	if changes.WeeklyGoal != nil || changes.BreakStart != nil {
+		currentStart, _ := timeutil.GetWeekBounds(timeutil.Now(cfg), cfg)
+		cfg.WeeklyGoal, cfg.BreakStart = ScheduledGoals(cfg, currentStart)
+		cfg.GoalHistory, cfg.WeekGoals = nil, nil
+	}
+	if changes.WeeklyGoal != nil {
+		cfg.WeeklyGoal = *changes.WeeklyGoal
+	}
+	if changes.BreakStart != nil {
+		cfg.BreakStart = *changes.BreakStart
+	}
+	if changes.SurplusMultiplier != nil {
+		rules := breakRules(cfg)
+		rules.SurplusMultiplier = *changes.SurplusMultiplier
+		cfg.BreakRules = &rules
+	}
+	if changes.Rollover != nil {
+		cfg.Rollover = *changes.Rollover
+	}
+	if changes.RestDays != nil {
+		cfg.RestDays = changes.RestDays
+	}
+	return cfg
+}
+
+// Simulate replays every completed week of state's logs under cfg, using the same
+// week summaries, rules engine, rollover and streak logic as the real views. state is not modified.
+func Simulate(state *data.AppState, cfg data.Config) SimResult {
+	sim := *state
+	sim.Config = cfg
+	RecalculateCarryover(&sim) // Assigns a fresh map, so the real carryover is untouched
+
+	var result SimResult
+	result.Streak, result.Longest = WeeklyStreaks(&sim)
+
+	firstWeek, ok := firstLoggedWeek(&sim)
+	if !ok {
+		return result
+	}
+	currentStart, _ := timeutil.GetWeekBounds(timeutil.Now(cfg), cfg)
+	for start := firstWeek; start.Before(currentStart); start = start.AddDate(0, 0, 7) {
+		summary := SummarizeWeek(&sim, start)
+		result.Weeks++
+		if summary.HasLogs && summary.Study >= summary.Goal {
+			result.GoalsMet++
+		}
+		result.Study += summary.Study
+		result.Surplus += max(summary.Study-summary.Goal, 0)
+		result.BreaksEarned += summary.Earned
+		result.BreaksGranted += summary.BreakStart
+		result.BreaksUsed += summary.BreaksUsed
+		result.BreaksLost += summary.Available - CarriedOver(summary.Available, cfg)
+	}
+	return result
+}
+
+// SyntheticState builds a history of `weeks` identical completed weeks ending last week.
+// pattern holds each day's study and break credits in week order, starting on the configured week start.
+func SyntheticState(cfg data.Config, pattern [][2]int, weeks int) *data.AppState {
+	state := &data.AppState{
+		Config:        cfg,
+		WeeklySurplus: make(map[string]int),
+		Carryover:     make(map[string]int),
+	}
+	currentStart, _ := timeutil.GetWeekBounds(timeutil.Now(cfg), cfg)
+	for week := weeks; week >= 1; week-- {
+		weekStart := currentStart.AddDate(0, 0, -7*week)
+		for i, credits := range pattern {
+			date := weekStart.AddDate(0, 0, i)
+			// Log mid-morning so day_starts_at never moves an entry to the previous day
+			at := date.Add(10 * time.Hour)
+			day := data.Day{Date: date.Format(data.DateFormat)}
+			if credits[0] > 0 {
+				day.Logs = append(day.Logs, data.Log{Type: data.LogTypeStudy, Timestamp: at, Amount: credits[0]})
+			}
+			if credits[1] > 0 {
+				day.Logs = append(day.Logs, data.Log{Type: data.LogTypeBreak, Timestamp: at.Add(time.Hour), Amount: credits[1]})
+			}
+			if len(day.Logs) > 0 {
+				state.Logs = append(state.Logs, day)
+			}
+		}
+	}
+	return state
+}
+
+// ParsePattern parses a week of "study[/breaks]" values separated by commas, e.g. "12/1,10,0,15/2,8,6,0".
+func ParsePattern(value string) ([][2]int, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 7 {
+		return nil, fmt.Errorf("invalid pattern: '%s'. Give 7 comma-separated days, e.g. 12/1,10,0,15/2,8,6,0", value)
+	}
+	pattern := make([][2]int, 7)
+	for i, part := range parts {
+		studyStr, breakStr, hasBreaks := strings.Cut(strings.TrimSpace(part), "/")
+		study, err := strconv.Atoi(studyStr)
+		if err != nil || study < 0 {
+			return nil, fmt.Errorf("invalid pattern day %d: '%s'. Use study or study/breaks, e.g. 12/1", i+1, part)
+		}
+		breaks := 0
+		if hasBreaks {
+			if breaks, err = strconv.Atoi(breakStr); err != nil || breaks < 0 {
+				return nil, fmt.Errorf("invalid pattern day %d: '%s'. Use study or study/breaks, e.g. 12/1", i+1, part)
+			}
+		}
+		pattern[i] = [2]int{study, breaks}
+	}
+	return pattern, nil
+}
+
+// ParseRollover parses a rollover setting of the form "none", "full", "capped:N" or "decay:PERCENT".
+func ParseRollover(value string) (data.Rollover, error) {
+	mode, amountStr, hasAmount := strings.Cut(strings.ToLower(strings.TrimSpace(value)), ":")
+	rollover := data.Rollover{Mode: mode}
+	if hasAmount {
+		amount, err := strconv.Atoi(amountStr)
+		if err != nil {
+			return rollover, fmt.Errorf("invalid rollover: '%s'. Use none, full, capped:N or decay:PERCENT", value)
+		}
+		switch mode {
+		case data.RolloverCapped:
+			rollover.Cap = amount
+		case data.RolloverDecay:
+			rollover.DecayPercent = amount
+		default:
+			return rollover, fmt.Errorf("invalid rollover: '%s'. Only capped and decay take a number", value)
+		}
+	}
+	return rollover, nil
+}
@@ -0,0 +1,96 @@
+package logic
+
+import (
+	"time"
+
+	"grain/internal/data"
+	"grain/internal/timeutil"
+)
+
+// streakRun tracks a run of consecutive successful periods while replaying history.
+type streakRun struct {
+	current data.StreakRecord
+	longest data.StreakRecord
+}
+
+// extend adds a successful period spanning [start, end] to the current run.
+func (r *streakRun) extend(start, end time.Time) {
+	if r.current.Length == 0 {
+		r.current.Start = start.Format(data.DateFormat)
+	}
+	r.current.Length++
+	r.current.End = end.Format(data.DateFormat)
+	if r.current.Length > r.longest.Length {
+		r.longest = r.current
+	}
+}
+
+// reset ends the current run.
+func (r *streakRun) reset() {
+	r.current = data.StreakRecord{}
+}
+
+// WeeklyStreaks replays every completed week from the first log and returns the current
+// and longest runs of weeks meeting their goal. Weeks taken entirely off or frozen are skipped.
+func WeeklyStreaks(state *data.AppState) (current, longest data.StreakRecord) {
+	currentStart, _ := timeutil.GetWeekBounds(timeutil.Now(state.Config), state.Config)
+	return weeklyStreaksUntil(state, currentStart)
+}
+
+// weeklyStreaksUntil replays the weeks before the week starting on until.
+func weeklyStreaksUntil(state *data.AppState, until time.Time) (current, longest data.StreakRecord) {
+	firstWeek, ok := firstLoggedWeek(state)
+	if !ok {
+		return current, longest
+	}
+
+	var run streakRun
+	for start := firstWeek; start.Before(until); start = start.AddDate(0, 0, 7) {
+		summary := SummarizeWeek(state, start)
+		switch {
+		case summary.FullyOff() || summary.Frozen:
+			// Weeks taken entirely off or frozen neither extend nor break the streak
+		case summary.HasLogs && summary.Study >= summary.Goal:
+			run.extend(summary.Start, summary.End)
+		default:
+			run.reset()
+		}
+	}
+	return run.current, run.longest
+}
+
+// DailyStreaks replays every day from the first log up to today and returns the current
+// and longest runs of "show-up" days with at least Config.DailyMinimum study credits.
+// Rest days and days off are skipped, and today only counts once its minimum is reached.
+func DailyStreaks(state *data.AppState) (current, longest data.StreakRecord) {
+	studyByDate := dailyStudy(state)
+	if len(state.Logs) == 0 {
+		return current, longest
+	}
+	first, err := timeutil.ParseDate(state.Logs[0].Date, state.Config)
+	if err != nil {
+		return current, longest
+	}
+	today := timeutil.LogicalDay(timeutil.Now(state.Config), state.Config)
+
+	minimum := state.Config.DailyMinimum
+	if minimum <= 0 {
+		minimum = 1
+	}
+
+	var run streakRun
+	for date := first; !date.After(today); date = date.AddDate(0, 0, 1) {
+		if !isWorkingDay(state, date) {
+			continue
+		}
+		switch {
+		case studyByDate[date.Format(data.DateFormat)] >= minimum:
+			run.extend(date, date)
+		case date.Equal(today):
+			// Today is still in progress, so it can't break the streak yet
+		default:
+			run.reset()
+		}
+	}
+	return run.current, run.longest
+}
@@ -0,0 +1,120 @@
+package logic
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"grain/internal/data"
+	"grain/internal/timeutil"
+)
+
+// logPastWeeks logs study on the first day of each of the weeks before the current one,
+// oldest first, with 0 meaning the week has no logs. It returns the weeks' start dates.
+func logPastWeeks(t *testing.T, state *data.AppState, amounts ...int) []time.Time {
+	t.Helper()
+	currentStart, _ := timeutil.GetWeekBounds(timeutil.Now(state.Config), state.Config)
+	starts := []time.Time{}
+	for i, amount := range amounts {
+		start := currentStart.AddDate(0, 0, -7*(len(amounts)-i))
+		starts = append(starts, start)
+		if amount > 0 {
+			date := start.Format(data.DateFormat)
+			state.Logs = append(state.Logs, data.Day{Date: date, Logs: []data.Log{entry(t, data.LogTypeStudy, date, 9, amount)}})
+		}
+	}
+	return starts
+}
+
+func TestDailyStreaks(t *testing.T) {
+	cfg := data.Config{Timezone: "UTC"}
+	today := timeutil.LogicalDay(timeutil.Now(cfg), cfg)
+	day := func(offset int) string { return today.AddDate(0, 0, offset).Format(data.DateFormat) }
+
+	tests := []struct {
+		name        string
+		minimum     int
+		study       map[int]int // Study per day, keyed by offset from today
+		rest        int         // Offset of a rest day, if not 0
+		off         int         // Offset of a day off, if not 0
+		wantCurrent int
+		wantLongest int
+		wantStart   string // Start of the current run, checked while one is running
+	}{
+		{name: "run up to yesterday", minimum: 10, study: map[int]int{-3: 10, -2: 12, -1: 10}, wantCurrent: 3, wantLongest: 3, wantStart: day(-3)},
+		{name: "today counts once its minimum is met", minimum: 10, study: map[int]int{-2: 10, -1: 10, 0: 10}, wantCurrent: 3, wantLongest: 3, wantStart: day(-2)},
+		{name: "today below the minimum doesn't break the run", minimum: 10, study: map[int]int{-2: 10, -1: 10, 0: 5}, wantCurrent: 2, wantLongest: 2, wantStart: day(-2)},
+		{name: "a day below the minimum breaks the run", minimum: 10, study: map[int]int{-5: 10, -4: 10, -3: 10, -2: 9, -1: 10}, wantCurrent: 1, wantLongest: 3, wantStart: day(-1)},
+		{name: "missing yesterday resets the run", minimum: 10, study: map[int]int{-4: 10, -3: 10, -2: 10}, wantCurrent: 0, wantLongest: 3},
+		{name: "rest days are skipped", minimum: 10, study: map[int]int{-3: 10, -1: 10}, rest: -2, wantCurrent: 2, wantLongest: 2, wantStart: day(-3)},
+		{name: "days off are skipped", minimum: 10, study: map[int]int{-3: 10, -1: 10}, off: -2, wantCurrent: 2, wantLongest: 2, wantStart: day(-3)},
+		{name: "unset minimum means any study", study: map[int]int{-2: 1, -1: 1}, wantCurrent: 2, wantLongest: 2, wantStart: day(-2)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := newTestState()
+			state.Config.DailyMinimum = tt.minimum
+			state.Config.RestDays = []string{}
+			if tt.rest != 0 {
+				state.Config.RestDays = []string{strings.ToLower(today.AddDate(0, 0, tt.rest).Weekday().String())}
+			}
+			if tt.off != 0 {
+				state.DaysOff = []data.DayOff{{Date: day(tt.off), Reason: "holiday"}}
+			}
+			for offset := -7; offset <= 0; offset++ {
+				if amount, ok := tt.study[offset]; ok {
+					state.Logs = append(state.Logs, data.Day{Date: day(offset), Logs: []data.Log{entry(t, data.LogTypeStudy, day(offset), 0, amount)}})
+				}
+			}
+
+			current, longest := DailyStreaks(state)
+			if current.Length != tt.wantCurrent || longest.Length != tt.wantLongest {
+				t.Errorf("current %d, longest %d, want %d and %d", current.Length, longest.Length, tt.wantCurrent, tt.wantLongest)
+			}
+			if tt.wantCurrent > 0 && current.Start != tt.wantStart {
+				t.Errorf("current run starts %s, want %s", current.Start, tt.wantStart)
+			}
+		})
+	}
+}
+
+func TestWeeklyStreaks(t *testing.T) {
+	tests := []struct {
+		name        string
+		study       []int // Study in each of the last four weeks, oldest first
+		frozen      int   // Index of a frozen week, if not -1
+		off         int   // Index of a week taken entirely off, if not -1
+		wantCurrent int
+		wantLongest int
+	}{
+		{name: "every goal met", study: []int{90, 95, 90, 100}, frozen: -1, off: -1, wantCurrent: 4, wantLongest: 4},
+		{name: "a missed week breaks the run", study: []int{90, 50, 90, 90}, frozen: -1, off: -1, wantCurrent: 2, wantLongest: 2},
+		{name: "a frozen miss is skipped", study: []int{90, 50, 90, 90}, frozen: 1, off: -1, wantCurrent: 3, wantLongest: 3},
+		{name: "a week off is skipped", study: []int{90, 0, 90, 90}, frozen: -1, off: 1, wantCurrent: 3, wantLongest: 3},
+		{name: "a week without logs breaks the run", study: []int{90, 0, 90, 90}, frozen: -1, off: -1, wantCurrent: 2, wantLongest: 2},
+		{name: "missing last week ends the run", study: []int{90, 90, 90, 40}, frozen: -1, off: -1, wantCurrent: 0, wantLongest: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := newTestState()
+			state.Config.RestDays = []string{}
+			starts := logPastWeeks(t, state, tt.study...)
+			if tt.frozen >= 0 {
+				state.Freezes = []data.Freeze{{Week: timeutil.GetWeekIDForDate(starts[tt.frozen], state.Config)}}
+			}
+			if tt.off >= 0 {
+				for i := 0; i < 7; i++ {
+					state.DaysOff = append(state.DaysOff, data.DayOff{Date: starts[tt.off].AddDate(0, 0, i).Format(data.DateFormat), Reason: "vacation"})
+				}
+			}
+			// Study in the current week doesn't count until it's over
+			today := timeutil.LogicalDay(timeutil.Now(state.Config), state.Config).Format(data.DateFormat)
+			state.Logs = append(state.Logs, data.Day{Date: today, Logs: []data.Log{entry(t, data.LogTypeStudy, today, 0, 5)}})
+
+			current, longest := WeeklyStreaks(state)
+			if current.Length != tt.wantCurrent || longest.Length != tt.wantLongest {
+				t.Errorf("current %d, longest %d, want %d and %d", current.Length, longest.Length, tt.wantCurrent, tt.wantLongest)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package logic
+
+import (
+	"fmt"
+	"math"
+
+	"grain/internal/data"
+	"grain/internal/timeutil"
+)
+
+// Tuning for goal suggestions
+const (
+	minSuggestWeeks = 3    // Completed weeks needed before suggesting anything
+	goalStretch     = 1.05 // Suggested goal sits this far above typical study
+	maxGoalStep     = 0.15 // Largest change to the goal suggested at once, as a share of the current goal
+	highHitRate     = 0.8  // Hit rate at which the goal is too comfortable to lower
+	lowHitRate      = 0.5  // Hit rate below which the goal is too hard to raise
+)
+
+// GoalSuggestion is a recommended weekly goal and break start, with the reasoning behind it.
+type GoalSuggestion struct {
+	Weeks         int      // Completed weeks analyzed
+	MedianStudy   float64  // Typical weekly study
+	HitRate       float64  // Share of weeks that met their goal
+	AverageEarned float64  // Average break credits earned per week
+	MedianBreaks  float64  // Typical break credits used per week
+	RanOut        int      // Weeks that used every available break credit
+	CurrentGoal   int      // Goal in force this week
+	CurrentBreaks int      // Break start in force this week
+	WeeklyGoal    int      // Recommended goal
+	BreakStart    int      // Recommended break start
+	Reasons       []string // Why, one step per line
+}
+
+// SuggestGoals recommends a weekly goal and break start from the last `weeks` completed weeks:
+// a small stretch above typical study, moved gradually and held back by the hit rate, and a
+// break start that covers the breaks actually taken beyond those earned.
+func SuggestGoals(state *data.AppState, weeks int) (GoalSuggestion, error) {
+	current := CurrentWeekSummary(state)
+	suggestion := GoalSuggestion{}
+	suggestion.CurrentGoal, suggestion.CurrentBreaks = GoalsFor(state.Config, current.Start)
+
+	firstWeek, ok := firstLoggedWeek(state)
+	studies, breaks, earned := []float64{}, []float64{}, 0
+	met := 0
+	for i := 1; ok && i <= weeks; i++ {
+		start := current.Start.AddDate(0, 0, -7*i)
+		if start.Before(firstWeek) {
+			break
+		}
+		summary := SummarizeWeek(state, start)
+		if summary.FullyOff() {
+			continue
+		}
+		studies = append(studies, float64(summary.Study))
+		breaks = append(breaks, float64(summary.BreaksUsed))
+		earned += summary.Earned
+		if summary.Study >= summary.Goal {
+			met++
+		}
+		if summary.Available == 0 && summary.BreaksUsed > 0 {
+			suggestion.RanOut++
+		}
+	}
+
+	suggestion.Weeks = len(studies)
+	if suggestion.Weeks < minSuggestWeeks {
+		return suggestion, fmt.Errorf("not enough history yet: %d completed weeks, need at least %d", suggestion.Weeks, minSuggestWeeks)
+	}
+	suggestion.MedianStudy = median(studies)
+	suggestion.MedianBreaks = median(breaks)
+	suggestion.AverageEarned = float64(earned) / float64(suggestion.Weeks)
+	suggestion.HitRate = float64(met) / float64(suggestion.Weeks)
+
+	// Goal: a small stretch above what you typically do
+	goal := int(math.Round(suggestion.MedianStudy * goalStretch))
+	suggestion.Reasons = append(suggestion.Reasons, fmt.Sprintf("You typically study %.0f credits a week; %d is a %.0f%% stretch on that.", suggestion.MedianStudy, goal, (goalStretch-1)*100))
+
+	// Move gradually, so one unusual month doesn't swing the goal
+	step := max(int(math.Round(float64(suggestion.CurrentGoal)*maxGoalStep)), 1)
+	if goal > suggestion.CurrentGoal+step || goal < suggestion.CurrentGoal-step {
+		goal = min(max(goal, suggestion.CurrentGoal-step), suggestion.CurrentGoal+step)
+		suggestion.Reasons = append(suggestion.Reasons, fmt.Sprintf("Changes are limited to %.0f%% at a time, so %d.", maxGoalStep*100, goal))
+	}
+
+	hitPercent := suggestion.HitRate * 100
+	switch {
+	case suggestion.HitRate >= highHitRate && goal < suggestion.CurrentGoal:
+		goal = suggestion.CurrentGoal
+		suggestion.Reasons = append(suggestion.Reasons, fmt.Sprintf("You met the goal in %.0f%% of weeks, so it isn't lowered.", hitPercent))
+	case suggestion.HitRate < lowHitRate && goal > suggestion.CurrentGoal:
+		goal = suggestion.CurrentGoal
+		suggestion.Reasons = append(suggestion.Reasons, fmt.Sprintf("You met the goal in only %.0f%% of weeks, so it isn't raised yet.", hitPercent))
+	default:
+		suggestion.Reasons = append(suggestion.Reasons, fmt.Sprintf("You met the goal in %.0f%% of weeks.", hitPercent))
+	}
+	suggestion.WeeklyGoal = max(goal, 1)
+
+	// Break start: enough for the breaks you actually take, beyond what you earn
+	breakStart := max(int(math.Round(suggestion.MedianBreaks-suggestion.AverageEarned)), 0)
+	suggestion.Reasons = append(suggestion.Reasons, fmt.Sprintf("You typically use %.0f break credits a week and earn %.1f, so start with %d.", suggestion.MedianBreaks, suggestion.AverageEarned, breakStart))
+	if breakStart < suggestion.CurrentBreaks/2 {
+		// Rest is part of sustainable, so don't take most of it away in one go
+		breakStart = suggestion.CurrentBreaks / 2
+		suggestion.Reasons = append(suggestion.Reasons, fmt.Sprintf("Breaks are cut by at most half at a time, so %d.", breakStart))
+	}
+	if suggestion.RanOut*2 > suggestion.Weeks && breakStart <= suggestion.CurrentBreaks {
+		breakStart = suggestion.CurrentBreaks + 1
+		suggestion.Reasons = append(suggestion.Reasons, fmt.Sprintf("You ran out of breaks in %d of %d weeks, so one more: %d.", suggestion.RanOut, suggestion.Weeks, breakStart))
+	}
+	suggestion.BreakStart = breakStart
+	return suggestion, nil
+}
+
+// ApplySuggestion adopts a suggestion from next week onward, leaving this week and earlier weeks untouched.
+func ApplySuggestion(cfg *data.Config, suggestion GoalSuggestion) (from string) {
+	currentStart, _ := timeutil.GetWeekBounds(timeutil.Now(*cfg), *cfg)
+	nextStart := currentStart.AddDate(0, 0, 7)
+	SetGoals(cfg, nextStart, suggestion.WeeklyGoal, suggestion.BreakStart)
+	return timeutil.GetWeekIDForDate(nextStart, *cfg)
+}
@@ -0,0 +1,138 @@
+package logic
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"grain/internal/data"
+	"grain/internal/timeutil"
+)
+
+// TargetProgress describes how a target is going and what it still takes.
+type TargetProgress struct {
+	Target          data.Target
+	Done            int  // Study credits counted so far
+	Remaining       int  // Credits still needed
+	DaysLeft        int  // Calendar days until the deadline, including today
+	WorkingDaysLeft int  // Days left that are neither rest days nor days off
+	RequiredWeekly  int  // Credits per week needed from here
+	Capacity        int  // Credits the weekly goals add up to from today to the deadline
+	Reachable       bool // Whether keeping the weekly goal pace would get there in time
+}
+
+// AddTarget validates and stores a new target starting today.
+func AddTarget(state *data.AppState, target data.Target) error {
+	target.Name = strings.TrimSpace(target.Name)
+	target.Tag = strings.ToLower(strings.TrimSpace(target.Tag))
+	if target.Name == "" {
+		return fmt.Errorf("target name must not be empty")
+	}
+	if target.Credits <= 0 {
+		return fmt.Errorf("target credits must be positive")
+	}
+	by, err := timeutil.ParseDate(target.By, state.Config)
+	if err != nil {
+		return fmt.Errorf("invalid deadline: '%s'. Use YYYY-MM-DD", target.By)
+	}
+	today := timeutil.LogicalDay(timeutil.Now(state.Config), state.Config)
+	if by.Before(today) {
+		return fmt.Errorf("deadline %s is already in the past", target.By)
+	}
+	if _, found := findTarget(state, target.Name); found {
+		return fmt.Errorf("a target named '%s' already exists", target.Name)
+	}
+
+	target.Created = today.Format(data.DateFormat)
+	state.Targets = append(state.Targets, target)
+	return nil
+}
+
+// RemoveTarget deletes a target by name (case-insensitive).
+func RemoveTarget(state *data.AppState, name string) error {
+	i, found := findTarget(state, name)
+	if !found {
+		return fmt.Errorf("no target named '%s'", name)
+	}
+	state.Targets = append(state.Targets[:i], state.Targets[i+1:]...)
+	return nil
+}
+
+// findTarget returns the index of the target with the given name.
+func findTarget(state *data.AppState, name string) (int, bool) {
+	for i, target := range state.Targets {
+		if strings.EqualFold(target.Name, strings.TrimSpace(name)) {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// ProgressFor computes a target's progress, countdown and required weekly rate.
+func ProgressFor(state *data.AppState, target data.Target) TargetProgress {
+	progress := TargetProgress{Target: target}
+
+	// Count study logged between creation and the deadline, filtered by tag if set
+	for _, day := range state.Logs {
+		if day.Date < target.Created || day.Date > target.By {
+			continue
+		}
+		for _, log := range day.Logs {
+			if log.Type == data.LogTypeStudy && (target.Tag == "" || log.Tag == target.Tag) {
+				progress.Done += log.Amount
+			}
+		}
+	}
+	progress.Remaining = max(target.Credits-progress.Done, 0)
+
+	today := timeutil.LogicalDay(timeutil.Now(state.Config), state.Config)
+	by, err := timeutil.ParseDate(target.By, state.Config)
+	if err == nil {
+		for date := today; !date.After(by); date = date.AddDate(0, 0, 1) {
+			progress.DaysLeft++
+			if isWorkingDay(state, date) {
+				progress.WorkingDaysLeft++
+			}
+		}
+	}
+
+	// Spread what's left over the working weeks remaining
+	workingPerWeek := 7 - len(timeutil.RestDays(state.Config))
+	if workingPerWeek <= 0 {
+		workingPerWeek = 7
+	}
+	if progress.Remaining > 0 && progress.WorkingDaysLeft > 0 {
+		progress.RequiredWeekly = (progress.Remaining*workingPerWeek + progress.WorkingDaysLeft - 1) / progress.WorkingDaysLeft
+	}
+
+	// Reachable if keeping each week's goal until the deadline covers what's left
+	if err == nil {
+		progress.Capacity = goalCapacity(state, today, by)
+	}
+	progress.Reachable = progress.Remaining == 0 || (progress.WorkingDaysLeft > 0 && progress.Capacity >= progress.Remaining)
+	return progress
+}
+
+// goalCapacity returns the study the weekly goals ask for from one day through another. Each week
+// counts its own goal, prorated for the working days of it that are left; debt isn't capacity, so
+// it's left out.
+func goalCapacity(state *data.AppState, from, to time.Time) int {
+	capacity := 0
+	weekStart, _ := timeutil.GetWeekBoundsForDate(from, state.Config)
+	for ; !weekStart.After(to); weekStart = weekStart.AddDate(0, 0, 7) {
+		left := 0
+		for i := 0; i < 7; i++ {
+			date := weekStart.AddDate(0, 0, i)
+			if !date.Before(from) && !date.After(to) && isWorkingDay(state, date) {
+				left++
+			}
+		}
+		if left == 0 {
+			continue
+		}
+		goal, _ := GoalsFor(state.Config, weekStart)
+		_, total := WorkingDays(state, weekStart)
+		capacity += proratedGoal(goal, left, total)
+	}
+	return capacity
+}
@@ -0,0 +1,74 @@
+package logic
+
+import (
+	"testing"
+	"time"
+
+	"grain/internal/data"
+	"grain/internal/timeutil"
+)
+
+func TestProgressForCapacity(t *testing.T) {
+	// Every day is a working day and the goal is 10 a day, so capacity is easy to count
+	cfg := data.Config{WeeklyGoal: 70, BreakStart: 12, Timezone: "UTC", RestDays: []string{}}
+	today := timeutil.LogicalDay(timeutil.Now(cfg), cfg)
+	currentStart, _ := timeutil.GetWeekBoundsForDate(today, cfg)
+	nextStart := currentStart.AddDate(0, 0, 7)
+	daysLeftThisWeek := 7 - int(today.Sub(currentStart).Hours()/24)
+	by := currentStart.AddDate(0, 0, 20) // The end of the week after next
+
+	offWeek := func(state *data.AppState, start int) {
+		from := currentStart.AddDate(0, 0, start)
+		AddDaysOff(state, from, from.AddDate(0, 0, 6), "holiday")
+	}
+	tests := []struct {
+		name         string
+		setup        func(state *data.AppState)
+		wantCapacity int
+	}{
+		{name: "plain weeks", wantCapacity: 10*daysLeftThisWeek + 140},
+		{name: "next week off", setup: func(state *data.AppState) { offWeek(state, 7) }, wantCapacity: 10*daysLeftThisWeek + 70},
+		{name: "this week off", setup: func(state *data.AppState) { offWeek(state, 0) }, wantCapacity: 140},
+		{
+			name: "debt this week isn't capacity",
+			setup: func(state *data.AppState) {
+				state.Config.Debt = data.Debt{Enabled: true, Percent: 100}
+				lastWeek := currentStart.AddDate(0, 0, -7)
+				state.Logs = []data.Day{{Date: lastWeek.Format(data.DateFormat), Logs: []data.Log{
+					{Type: data.LogTypeStudy, Timestamp: lastWeek.Add(9 * time.Hour), Amount: 10},
+				}}}
+				if debt := CurrentWeekSummary(state).Debt; debt != 60 {
+					t.Fatalf("debt = %d, want 60", debt)
+				}
+			},
+			wantCapacity: 10*daysLeftThisWeek + 140,
+		},
+		{
+			name: "each week counts its own goal",
+			setup: func(state *data.AppState) {
+				state.Config.WeekGoals = map[string]int{timeutil.GetWeekIDForDate(currentStart, cfg): 700}
+				SetGoals(&state.Config, nextStart, 140, 12)
+			},
+			wantCapacity: 100*daysLeftThisWeek + 280,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := newTestState()
+			state.Config = cfg
+			if tt.setup != nil {
+				tt.setup(state)
+			}
+			target := data.Target{Name: "exam", Credits: tt.wantCapacity, By: by.Format(data.DateFormat), Created: today.Format(data.DateFormat)}
+			progress := ProgressFor(state, target)
+			if progress.Capacity != tt.wantCapacity || !progress.Reachable {
+				t.Errorf("capacity = %d (reachable %v), want %d and reachable", progress.Capacity, progress.Reachable, tt.wantCapacity)
+			}
+
+			target.Credits++
+			if progress := ProgressFor(state, target); progress.Reachable {
+				t.Errorf("%d credits with a capacity of %d is reachable", target.Credits, progress.Capacity)
+			}
+		})
+	}
+}
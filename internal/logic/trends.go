@@ -0,0 +1,133 @@
+package logic
+
+import (
+	"grain/internal/data"
+	"grain/internal/timeutil"
+)
+
+// Rolling average windows, in weeks
+const (
+	shortWindow = 4
+	longWindow  = 12
+)
+
+// sustainedDecline is how many weeks in a row must fall below their rolling average to call it a decline.
+const sustainedDecline = 3
+
+// Trend describes how weekly study has been moving recently.
+type Trend struct {
+	Weeks        []int   // Study credits of each completed week since the first log, oldest first (weeks taken off skipped)
+	Average4     float64 // Average of the last 4 completed weeks
+	Average12    float64 // Average of the last 12 completed weeks
+	Slope        float64 // Least-squares change in weekly study per week over the last 12 weeks
+	SlopeWeeks   int     // Weeks the slope was fitted to
+	ThisMonth    int     // Study credits this month so far
+	LastMonth    int     // Study credits over the same days of last month
+	MonthDays    int     // Days of this month compared
+	DecliningFor int     // Latest consecutive weeks each below the 4-week average before it
+}
+
+// Direction summarizes the slope as "up", "down" or "flat" (less than a credit a week either way).
+func (t Trend) Direction() string {
+	switch {
+	case t.Slope >= 1:
+		return "up"
+	case t.Slope <= -1:
+		return "down"
+	default:
+		return "flat"
+	}
+}
+
+// SustainedDecline reports whether enough weeks in a row fell below their rolling average to need attention.
+func (t Trend) SustainedDecline() bool {
+	return t.DecliningFor >= sustainedDecline
+}
+
+// AnalyzeTrend computes rolling averages, the trend of weekly study and the month-over-month comparison.
+func AnalyzeTrend(state *data.AppState) Trend {
+	var trend Trend
+
+	if firstWeek, ok := firstLoggedWeek(state); ok {
+		currentStart, _ := timeutil.GetWeekBounds(timeutil.Now(state.Config), state.Config)
+		for start := firstWeek; start.Before(currentStart); start = start.AddDate(0, 0, 7) {
+			summary := SummarizeWeek(state, start)
+			if !summary.FullyOff() {
+				trend.Weeks = append(trend.Weeks, summary.Study)
+			}
+		}
+	}
+
+	trend.Average4 = mean(lastWeeks(trend.Weeks, shortWindow))
+	trend.Average12 = mean(lastWeeks(trend.Weeks, longWindow))
+	recent := lastWeeks(trend.Weeks, longWindow)
+	trend.Slope, trend.SlopeWeeks = slope(recent), len(recent)
+
+	// Walk back from the latest week while each one trails the average of the 4 before it
+	for i := len(trend.Weeks) - 1; i >= shortWindow; i-- {
+		if float64(trend.Weeks[i]) >= mean(toFloats(trend.Weeks[i-shortWindow:i])) {
+			break
+		}
+		trend.DecliningFor++
+	}
+
+	trend.ThisMonth, trend.LastMonth, trend.MonthDays = monthToDate(state)
+	return trend
+}
+
+// monthToDate sums study this month so far and over the same days of last month.
+func monthToDate(state *data.AppState) (thisMonth, lastMonth, days int) {
+	today := timeutil.LogicalDay(timeutil.Now(state.Config), state.Config)
+	monthStart := today.AddDate(0, 0, 1-today.Day())
+	prevStart := monthStart.AddDate(0, -1, 0)
+	days = today.Day()
+	// A short previous month is compared in full rather than spilling into this one
+	prevEnd := prevStart.AddDate(0, 0, days-1)
+	if !prevEnd.Before(monthStart) {
+		prevEnd = monthStart.AddDate(0, 0, -1)
+	}
+
+	studyOn := dailyStudy(state)
+	for date := monthStart; !date.After(today); date = date.AddDate(0, 0, 1) {
+		thisMonth += studyOn[date.Format(data.DateFormat)]
+	}
+	for date := prevStart; !date.After(prevEnd); date = date.AddDate(0, 0, 1) {
+		lastMonth += studyOn[date.Format(data.DateFormat)]
+	}
+	return thisMonth, lastMonth, days
+}
+
+// lastWeeks returns up to n of the most recent weekly totals as floats.
+func lastWeeks(weeks []int, n int) []float64 {
+	if len(weeks) > n {
+		weeks = weeks[len(weeks)-n:]
+	}
+	return toFloats(weeks)
+}
+
+// toFloats converts weekly totals for the statistics helpers.
+func toFloats(values []int) []float64 {
+	floats := make([]float64, len(values))
+	for i, v := range values {
+		floats[i] = float64(v)
+	}
+	return floats
+}
+
+// slope returns the least-squares slope of values against their index (0 with fewer than 3 points).
+func slope(values []float64) float64 {
+	if len(values) < 3 {
+		return 0
+	}
+	xs := make([]float64, len(values))
+	for i := range xs {
+		xs[i] = float64(i)
+	}
+	meanX, meanY := mean(xs), mean(values)
+	var cov, varX float64
+	for i := range values {
+		cov += (xs[i] - meanX) * (values[i] - meanY)
+		varX += (xs[i] - meanX) * (xs[i] - meanX)
+	}
+	return cov / varX
+}
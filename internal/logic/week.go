@@ -0,0 +1,232 @@
+package logic
+
+import (
+	"time"
+
+	"grain/internal/data"
+	"grain/internal/timeutil"
+)
+
+// WeekSummary holds the numbers every weekly view is built from, so they all agree.
+type WeekSummary struct {
+	ID          string
+	Start, End  time.Time
+	Study       int  // Study credits counted towards the goal
+	BreaksUsed  int  // Break credits spent
+	Goal        int  // Effective study goal for the week (BaseGoal + Debt)
+	FullGoal    int  // Goal that applied to this week before days off and debt
+	BaseGoal    int  // FullGoal prorated for days off
+	DaysOff     int  // Non-rest days marked as days off
+	WorkingDays int  // Non-rest days left after days off
+	Debt        int  // Shortfall carried forward from the previous week
+	BreakStart  int  // Break credits granted at the start of the week
+	Carryover   int  // Unused break credits rolled over from the previous week
+	Earned      int  // Break credits earned under the configured break rules
+	Available   int  // Break credits left to spend
+	Bonus       int  // Rest-day study kept as bonus credits outside the goal
+	HasLogs     bool // Whether any counted day had entries
+	Frozen      bool // Whether a streak freeze was spent on this week
+}
+
+// SummarizeWeek computes the summary for the week starting on weekStart.
+func SummarizeWeek(state *data.AppState, weekStart time.Time) WeekSummary {
+	summary := WeekSummary{
+		ID:    timeutil.GetWeekIDForDate(weekStart, state.Config),
+		Start: weekStart,
+		End:   weekStart.AddDate(0, 0, 6),
+	}
+	summary.FullGoal, summary.BreakStart = GoalsFor(state.Config, weekStart)
+	working, total := WorkingDays(state, weekStart)
+	summary.WorkingDays = working
+	summary.DaysOff = total - working
+	summary.BaseGoal = proratedGoal(summary.FullGoal, working, total)
+	summary.Debt = previousWeekDebt(state, weekStart)
+	summary.Goal = summary.BaseGoal + summary.Debt
+	summary.Study, summary.BreaksUsed, summary.HasLogs = weekTotals(state, weekStart)
+	summary.Bonus = bonusStudy(state, weekStart)
+	summary.Frozen = IsFrozen(state, summary.ID)
+	summary.Earned = EarnedBreaks(summary.Study, summary.Goal, state.Config)
+	summary.Carryover = state.Carryover[summary.ID]
+
+	// Available breaks = Starting breaks + Rollover + Breaks earned this week - Breaks used
+	summary.Available = summary.BreakStart + summary.Carryover + summary.Earned - summary.BreaksUsed
+	if summary.Available < 0 {
+		summary.Available = 0 // Cannot have negative available breaks
+	}
+	return summary
+}
+
+// CurrentWeekSummary summarizes the week containing the current time.
+func CurrentWeekSummary(state *data.AppState) WeekSummary {
+	startOfWeek, _ := timeutil.GetWeekBounds(timeutil.Now(state.Config), state.Config)
+	return SummarizeWeek(state, startOfWeek)
+}
+
+// FullyOff reports whether every working day of the week was taken off.
+func (s WeekSummary) FullyOff() bool {
+	return s.DaysOff > 0 && s.WorkingDays == 0
+}
+
+// Budget returns the break credits the week started with, including rollover.
+func (s WeekSummary) Budget() int {
+	return s.BreakStart + s.Carryover
+}
+
+// previousWeekDebt returns the part of the previous week's shortfall carried into the week starting on weekStart.
+// Only that week's base goal counts, so one bad week can't snowball, and weeks before the first log never create debt.
+func previousWeekDebt(state *data.AppState, weekStart time.Time) int {
+	if !state.Config.Debt.Enabled {
+		return 0
+	}
+	firstWeek, ok := firstLoggedWeek(state)
+	prevStart := weekStart.AddDate(0, 0, -7)
+	if !ok || prevStart.Before(firstWeek) {
+		return 0
+	}
+	prevStudy, _, _ := weekTotals(state, prevStart)
+	prevGoal, _ := GoalsFor(state.Config, prevStart)
+	working, total := WorkingDays(state, prevStart)
+	return GoalDebt(proratedGoal(prevGoal, working, total)-prevStudy, state.Config)
+}
+
+// RecalculateCarryover replays every week from the first logged one up to the current week,
+// storing the break credits each week inherited under the rollover policy.
+func RecalculateCarryover(state *data.AppState) {
+	state.Carryover = make(map[string]int)
+
+	firstWeek, ok := firstLoggedWeek(state)
+	if !ok {
+		return
+	}
+	currentStart, _ := timeutil.GetWeekBounds(timeutil.Now(state.Config), state.Config)
+
+	for start := firstWeek; start.Before(currentStart); start = start.AddDate(0, 0, 7) {
+		summary := SummarizeWeek(state, start)
+		if carried := CarriedOver(summary.Available, state.Config); carried > 0 {
+			nextID := timeutil.GetWeekIDForDate(start.AddDate(0, 0, 7), state.Config)
+			state.Carryover[nextID] = carried
+		}
+	}
+}
+
+// weeklySurplus computes the break credits earned in every week from the first logged one up to the current week.
+func weeklySurplus(state *data.AppState) map[string]int {
+	surplus := make(map[string]int)
+	firstWeek, ok := firstLoggedWeek(state)
+	if !ok {
+		return surplus
+	}
+	currentStart, _ := timeutil.GetWeekBounds(timeutil.Now(state.Config), state.Config)
+	for start := firstWeek; !start.After(currentStart); start = start.AddDate(0, 0, 7) {
+		summary := SummarizeWeek(state, start)
+		surplus[summary.ID] = summary.Earned
+	}
+	return surplus
+}
+
+// firstLoggedWeek returns the start of the week containing the earliest valid logged day.
+func firstLoggedWeek(state *data.AppState) (time.Time, bool) {
+	for _, day := range state.Logs {
+		if dayDate, err := timeutil.ParseDate(day.Date, state.Config); err == nil {
+			start, _ := timeutil.GetWeekBoundsForDate(dayDate, state.Config)
+			return start, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// CreditWeekStart returns the start of the week a logical day's credits count towards.
+// Working days count towards their own week; rest days follow the rest-day policy,
+// and ok is false when they don't count towards any week (refuse or bonus).
+func CreditWeekStart(date time.Time, cfg data.Config) (weekStart time.Time, ok bool) {
+	if !timeutil.IsRestDay(date, cfg) {
+		weekStart, _ = timeutil.GetWeekBoundsForDate(date, cfg)
+		return weekStart, true
+	}
+
+	step := 0
+	switch cfg.RestDayPolicy {
+	case data.RestDayPrevious:
+		step = -1
+	case data.RestDayNext:
+		step = 1
+	default:
+		return time.Time{}, false
+	}
+
+	// Walk to the nearest working day in the chosen direction
+	for i := 1; i <= 7; i++ {
+		candidate := date.AddDate(0, 0, i*step)
+		if !timeutil.IsRestDay(candidate, cfg) {
+			weekStart, _ = timeutil.GetWeekBoundsForDate(candidate, cfg)
+			return weekStart, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// weekTotals sums the study and break credits that count towards the week starting on weekStart.
+// Rest-day logs only count when the rest-day policy assigns them to this week; found reports
+// whether any counted day had entries. Under the bonus policy, rest-day study stays out of the
+// goal but rest-day breaks are still charged to the rest day's own week.
+func weekTotals(state *data.AppState, weekStart time.Time) (study, breaks int, found bool) {
+	// Rest days just outside the week may be credited to it, so look one week either side
+	first := weekStart.AddDate(0, 0, -7).Format(data.DateFormat)
+	last := weekStart.AddDate(0, 0, 13).Format(data.DateFormat)
+
+	for _, day := range state.Logs {
+		// Dates are stored as YYYY-MM-DD, so string order matches chronological order
+		if day.Date < first || day.Date > last {
+			continue
+		}
+		dayDate, err := timeutil.ParseDate(day.Date, state.Config)
+		if err != nil {
+			continue
+		}
+		credited, ok := CreditWeekStart(dayDate, state.Config)
+		bonusDay := !ok && timeutil.IsRestDay(dayDate, state.Config) && state.Config.RestDayPolicy == data.RestDayBonus
+		if bonusDay {
+			credited, _ = timeutil.GetWeekBoundsForDate(dayDate, state.Config)
+		}
+		if (!ok && !bonusDay) || !credited.Equal(weekStart) {
+			continue
+		}
+		found = found || !bonusDay
+		for _, log := range day.Logs {
+			// Borrowed breaks come out of the loan, and study spent repaying it doesn't count twice
+			if log.Type == data.LogTypeStudy && !bonusDay {
+				study += log.Amount - log.Repaid
+			} else if log.Type == data.LogTypeBreak {
+				breaks += log.Amount - log.Borrowed
+			}
+		}
+	}
+	return study, breaks, found
+}
+
+// bonusStudy sums study logged on rest days within the week under the bonus policy.
+// These credits are kept and shown but never count towards the goal.
+func bonusStudy(state *data.AppState, weekStart time.Time) int {
+	if state.Config.RestDayPolicy != data.RestDayBonus {
+		return 0
+	}
+	first := weekStart.Format(data.DateFormat)
+	last := weekStart.AddDate(0, 0, 6).Format(data.DateFormat)
+
+	bonus := 0
+	for _, day := range state.Logs {
+		if day.Date < first || day.Date > last {
+			continue
+		}
+		dayDate, err := timeutil.ParseDate(day.Date, state.Config)
+		if err != nil || !timeutil.IsRestDay(dayDate, state.Config) {
+			continue
+		}
+		for _, log := range day.Logs {
+			if log.Type == data.LogTypeStudy {
+				bonus += log.Amount
+			}
+		}
+	}
+	return bonus
+}
@@ -0,0 +1,153 @@
+package logic
+
+import (
+	"testing"
+
+	"grain/internal/data"
+)
+
+func TestRecalculateCarryover(t *testing.T) {
+	// Each week starts 12 break credits; the week of Aug 31 spends 2 and the next one 4
+	weeks := []string{"2026-37", "2026-38", "2026-39"}
+	tests := []struct {
+		name     string
+		rollover data.Rollover
+		want     []int // Credits carried into each week
+	}{
+		{name: "none", rollover: data.Rollover{Mode: data.RolloverNone}, want: []int{0, 0, 0}},
+		{name: "unset", want: []int{0, 0, 0}},
+		{name: "full", rollover: data.Rollover{Mode: data.RolloverFull}, want: []int{10, 18, 30}},
+		{name: "capped", rollover: data.Rollover{Mode: data.RolloverCapped, Cap: 6}, want: []int{6, 6, 6}},
+		{name: "decay", rollover: data.Rollover{Mode: data.RolloverDecay, DecayPercent: 50}, want: []int{5, 6, 9}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := newTestState()
+			state.Config.Rollover = tt.rollover
+			state.Logs = []data.Day{
+				{Date: "2026-09-01", Logs: []data.Log{
+					entry(t, data.LogTypeStudy, "2026-09-01", 9, 10),
+					entry(t, data.LogTypeBreak, "2026-09-01", 11, 2),
+				}},
+				{Date: "2026-09-08", Logs: []data.Log{entry(t, data.LogTypeBreak, "2026-09-08", 11, 4)}},
+			}
+			state.Carryover = map[string]int{"2026-20": 99} // Stale entries don't survive a replay
+
+			RecalculateCarryover(state)
+			for i, week := range weeks {
+				if got := state.Carryover[week]; got != tt.want[i] {
+					t.Errorf("carryover into %s = %d, want %d", week, got, tt.want[i])
+				}
+			}
+			if _, found := state.Carryover["2026-20"]; found {
+				t.Errorf("stale carryover kept: %v", state.Carryover)
+			}
+			if _, found := state.Carryover["2026-36"]; found {
+				t.Errorf("carryover into the first logged week: %v", state.Carryover)
+			}
+		})
+	}
+}
+
+func TestCreditWeekStart(t *testing.T) {
+	tests := []struct {
+		name      string
+		weekStart string
+		restDays  []string
+		policy    string
+		date      string
+		want      string // Start of the credited week, empty when the day isn't credited
+	}{
+		{name: "working day", policy: data.RestDayRefuse, date: "2026-09-09", want: "2026-09-07"},
+		{name: "refuse", policy: data.RestDayRefuse, date: "2026-09-13"},
+		{name: "unset policy refuses", date: "2026-09-13"},
+		{name: "bonus isn't credited to a week", policy: data.RestDayBonus, date: "2026-09-13"},
+		{name: "previous", policy: data.RestDayPrevious, date: "2026-09-13", want: "2026-09-07"},
+		{name: "next crosses into the following week", policy: data.RestDayNext, date: "2026-09-13", want: "2026-09-14"},
+		{name: "saturday week, previous crosses back", weekStart: "saturday", restDays: []string{"saturday"}, policy: data.RestDayPrevious, date: "2026-09-12", want: "2026-09-05"},
+		{name: "saturday week, next", weekStart: "saturday", restDays: []string{"saturday"}, policy: data.RestDayNext, date: "2026-09-12", want: "2026-09-12"},
+		{name: "sunday week, next crosses forward", weekStart: "sunday", restDays: []string{"saturday"}, policy: data.RestDayNext, date: "2026-09-12", want: "2026-09-13"},
+		{name: "sunday week, previous", weekStart: "sunday", restDays: []string{"saturday"}, policy: data.RestDayPrevious, date: "2026-09-12", want: "2026-09-06"},
+		{name: "next skips a rest weekend", restDays: []string{"saturday", "sunday"}, policy: data.RestDayNext, date: "2026-09-12", want: "2026-09-14"},
+		{name: "previous skips a rest weekend", restDays: []string{"saturday", "sunday"}, policy: data.RestDayPrevious, date: "2026-09-13", want: "2026-09-07"},
+		{
+			name:     "no working day to credit",
+			restDays: []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"},
+			policy:   data.RestDayPrevious,
+			date:     "2026-09-09",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := data.Config{Timezone: "UTC", WeekStart: tt.weekStart, RestDays: tt.restDays, RestDayPolicy: tt.policy}
+			got, ok := CreditWeekStart(at(t, tt.date, 0), cfg)
+			if tt.want == "" {
+				if ok {
+					t.Errorf("credited to the week of %s, want no week", got.Format(data.DateFormat))
+				}
+				return
+			}
+			if !ok || got.Format(data.DateFormat) != tt.want {
+				t.Errorf("got %s (ok %v), want %s", got.Format(data.DateFormat), ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestRestDayPolicyTotals(t *testing.T) {
+	type totals struct{ study, breaks, bonus int }
+	day := func(date string, study, breaks int) data.Day {
+		logs := []data.Log{entry(t, data.LogTypeStudy, date, 9, study)}
+		if breaks > 0 {
+			logs = append(logs, entry(t, data.LogTypeBreak, date, 11, breaks))
+		}
+		return data.Day{Date: date, Logs: logs}
+	}
+	// A Monday week resting on Sunday, and a Saturday week resting on its first day
+	mondayWeek := []data.Day{day("2026-09-09", 10, 2), day("2026-09-13", 5, 3), day("2026-09-14", 7, 0)}
+	saturdayWeek := []data.Day{day("2026-09-11", 10, 0), day("2026-09-12", 5, 3), day("2026-09-13", 7, 0)}
+
+	tests := []struct {
+		name      string
+		weekStart string
+		restDays  []string
+		policy    string
+		days      []data.Day
+		weeks     []string // The rest day's week and the one after it
+		want      []totals
+	}{
+		{name: "refuse", policy: data.RestDayRefuse, days: mondayWeek, weeks: []string{"2026-09-07", "2026-09-14"}, want: []totals{{10, 2, 0}, {7, 0, 0}}},
+		{name: "previous", policy: data.RestDayPrevious, days: mondayWeek, weeks: []string{"2026-09-07", "2026-09-14"}, want: []totals{{15, 5, 0}, {7, 0, 0}}},
+		{name: "next", policy: data.RestDayNext, days: mondayWeek, weeks: []string{"2026-09-07", "2026-09-14"}, want: []totals{{10, 2, 0}, {12, 3, 0}}},
+		// Bonus study stays out of the goal, but the breaks are still spent in the rest day's week
+		{name: "bonus", policy: data.RestDayBonus, days: mondayWeek, weeks: []string{"2026-09-07", "2026-09-14"}, want: []totals{{10, 5, 5}, {7, 0, 0}}},
+		{
+			name: "saturday week, previous", weekStart: "saturday", restDays: []string{"saturday"}, policy: data.RestDayPrevious,
+			days: saturdayWeek, weeks: []string{"2026-09-05", "2026-09-12"}, want: []totals{{15, 3, 0}, {7, 0, 0}},
+		},
+		{
+			name: "saturday week, next", weekStart: "saturday", restDays: []string{"saturday"}, policy: data.RestDayNext,
+			days: saturdayWeek, weeks: []string{"2026-09-05", "2026-09-12"}, want: []totals{{10, 0, 0}, {12, 3, 0}},
+		},
+		{
+			name: "saturday week, bonus", weekStart: "saturday", restDays: []string{"saturday"}, policy: data.RestDayBonus,
+			days: saturdayWeek, weeks: []string{"2026-09-05", "2026-09-12"}, want: []totals{{10, 0, 0}, {7, 3, 5}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := newTestState()
+			state.Config.WeekStart = tt.weekStart
+			state.Config.RestDays = tt.restDays
+			state.Config.RestDayPolicy = tt.policy
+			state.Logs = tt.days
+			for i, week := range tt.weeks {
+				summary := weekOf(t, state, week)
+				got := totals{summary.Study, summary.BreaksUsed, summary.Bonus}
+				if got != tt.want[i] {
+					t.Errorf("week of %s: study/breaks/bonus = %v, want %v", week, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
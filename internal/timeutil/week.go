@@ -2,42 +2,246 @@ package timeutil
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"grain/internal/data"
 )
 
-// GetWeekBounds returns the start (Monday) and end (Sunday) dates for the week containing the given time.
-func GetWeekBounds(t time.Time) (start, end time.Time) {
-	// Go's Weekday starts with Sunday=0, Monday=1, ..., Saturday=6
-	weekday := t.Weekday()
-	// Adjust to make Monday the start of the week (Monday=0, ..., Sunday=6)
-	adjWeekday := int(weekday) - 1
-	if adjWeekday < 0 {
-		adjWeekday = 6 // Sunday
+// locations caches loaded time zones so hot aggregation loops don't re-read tzdata.
+var locations = map[string]*time.Location{}
+
+// Location returns the configured home time zone, falling back to the system zone.
+// All day and week grouping happens in this zone so travel and DST changes don't move entries around.
+func Location(cfg data.Config) *time.Location {
+	if cfg.Timezone == "" {
+		return time.Local
+	}
+	if loc, ok := locations[cfg.Timezone]; ok {
+		return loc
+	}
+	loc, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	locations[cfg.Timezone] = loc
+	return loc
+}
+
+// Now returns the current time in the home time zone.
+func Now(cfg data.Config) time.Time {
+	return time.Now().In(Location(cfg))
+}
+
+// ParseDate parses a YYYY-MM-DD string as midnight in the home time zone.
+func ParseDate(dateStr string, cfg data.Config) (time.Time, error) {
+	return time.ParseInLocation(data.DateFormat, dateStr, Location(cfg))
+}
+
+// ParseDateRange parses "YYYY-MM-DD" or "YYYY-MM-DD..YYYY-MM-DD" into an inclusive date range.
+func ParseDateRange(value string, cfg data.Config) (from, to time.Time, err error) {
+	fromStr, toStr, isRange := strings.Cut(value, "..")
+	if !isRange {
+		toStr = fromStr
+	}
+	from, err = ParseDate(strings.TrimSpace(fromStr), cfg)
+	if err != nil {
+		return from, to, fmt.Errorf("invalid date range: '%s'. Use YYYY-MM-DD or YYYY-MM-DD..YYYY-MM-DD", value)
+	}
+	to, err = ParseDate(strings.TrimSpace(toStr), cfg)
+	if err != nil {
+		return from, to, fmt.Errorf("invalid date range: '%s'. Use YYYY-MM-DD or YYYY-MM-DD..YYYY-MM-DD", value)
+	}
+	if to.Before(from) {
+		return from, to, fmt.Errorf("invalid date range: '%s'. The end date is before the start date", value)
+	}
+	return from, to, nil
+}
+
+// ParseDay parses "today", "tomorrow", a weekday name (its next occurrence, today included)
+// or a YYYY-MM-DD date into a logical day.
+func ParseDay(value string, cfg data.Config) (time.Time, error) {
+	today := LogicalDay(Now(cfg), cfg)
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "today":
+		return today, nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	}
+	if weekday, err := ParseWeekday(value); err == nil {
+		return today.AddDate(0, 0, (int(weekday)-int(today.Weekday())+7)%7), nil
+	}
+	date, err := ParseDate(strings.TrimSpace(value), cfg)
+	if err != nil {
+		return date, fmt.Errorf("invalid day: '%s'. Use 'today', 'tomorrow', a weekday or YYYY-MM-DD", value)
+	}
+	return date, nil
+}
+
+// ParseWeekday converts a weekday name such as "monday" or "Sun" into a time.Weekday.
+func ParseWeekday(name string) (time.Weekday, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		full := strings.ToLower(d.String())
+		if name == full || (len(name) >= 3 && strings.HasPrefix(full, name)) {
+			return d, nil
+		}
+	}
+	return time.Sunday, fmt.Errorf("unknown weekday: '%s'", name)
+}
+
+// WeekStartDay returns the weekday a week begins on according to the config (default Monday).
+func WeekStartDay(cfg data.Config) time.Weekday {
+	if cfg.WeekStart == "" {
+		return time.Monday
+	}
+	day, err := ParseWeekday(cfg.WeekStart)
+	if err != nil {
+		return time.Monday
+	}
+	return day
+}
+
+// RestDays returns the configured rest weekdays. A nil list means the original Sunday-only default.
+func RestDays(cfg data.Config) []time.Weekday {
+	if cfg.RestDays == nil {
+		return []time.Weekday{time.Sunday}
+	}
+	days := []time.Weekday{}
+	for _, name := range cfg.RestDays {
+		if day, err := ParseWeekday(name); err == nil {
+			days = append(days, day)
+		}
 	}
+	return days
+}
+
+// IsRestDay reports whether the given calendar date falls on a configured rest day.
+func IsRestDay(date time.Time, cfg data.Config) bool {
+	for _, day := range RestDays(cfg) {
+		if date.Weekday() == day {
+			return true
+		}
+	}
+	return false
+}
+
+// DayStart returns the configured hour and minute at which a logical day begins (default 00:00).
+func DayStart(cfg data.Config) (hour, minute int) {
+	if cfg.DayStartsAt == "" {
+		return 0, 0
+	}
+	hour, minute, err := ParseClock(cfg.DayStartsAt)
+	if err != nil {
+		return 0, 0
+	}
+	return hour, minute
+}
+
+// ParseClock parses a wall-clock time in HH:MM form.
+func ParseClock(value string) (hour, minute int, err error) {
+	parsed, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid time of day: '%s'. Use HH:MM", value)
+	}
+	return parsed.Hour(), parsed.Minute(), nil
+}
+
+// ClockOnDay returns the moment a wall-clock time (HH:MM) happens on a logical day.
+// Times before day_starts_at fall on the following calendar date, as they do for logging.
+func ClockOnDay(day time.Time, clock string, cfg data.Config) (time.Time, error) {
+	hour, minute, err := ParseClock(clock)
+	if err != nil {
+		return time.Time{}, err
+	}
+	t := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, Location(cfg))
+	startHour, startMinute := DayStart(cfg)
+	if hour*60+minute < startHour*60+startMinute {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
 
-	// Calculate the start of the week (Monday)
-	start = t.AddDate(0, 0, -adjWeekday)
-	// Calculate the end of the week (Sunday)
+// LogicalDay returns the calendar date (at midnight, home time zone) a timestamp belongs to.
+// Times before the configured day_starts_at are counted towards the previous day,
+// so a 1 a.m. session still belongs to the evening it started in.
+func LogicalDay(t time.Time, cfg data.Config) time.Time {
+	t = t.In(Location(cfg))
+	hour, minute := DayStart(cfg)
+	date := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	if t.Hour()*60+t.Minute() < hour*60+minute {
+		date = date.AddDate(0, 0, -1)
+	}
+	return date
+}
+
+// GetWeekBounds returns the first and last dates of the week containing the given time,
+// using the configured week start day and day rollover.
+func GetWeekBounds(t time.Time, cfg data.Config) (start, end time.Time) {
+	return GetWeekBoundsForDate(LogicalDay(t, cfg), cfg)
+}
+
+// GetWeekBoundsForDate returns the first and last dates of the week containing a calendar date.
+// Unlike GetWeekBounds, the date is taken as-is without applying the day rollover.
+func GetWeekBoundsForDate(date time.Time, cfg data.Config) (start, end time.Time) {
+	// Number of days since the most recent week start (0..6)
+	offset := (int(date.Weekday()) - int(WeekStartDay(cfg)) + 7) % 7
+
+	// Calculate the start of the week
+	start = date.AddDate(0, 0, -offset)
+	// Calculate the end of the week
 	end = start.AddDate(0, 0, 6)
 
 	// Ensure we are using the date part only, zeroing out time
-	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, t.Location())
-	end = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, t.Location())
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, date.Location())
+	end = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, date.Location())
 
 	return start, end
 }
 
 // GetWeekID generates a unique string identifier for the week containing the given time (e.g., "2024-23").
-func GetWeekID(t time.Time) string {
-	year, week := t.ISOWeek() // ISO week starts on Monday
+func GetWeekID(t time.Time, cfg data.Config) string {
+	return GetWeekIDForDate(LogicalDay(t, cfg), cfg)
+}
+
+// GetWeekIDForDate returns the week ID for a calendar date without applying the day rollover.
+// The ID is the ISO week of the week's middle day, which matches the plain ISO week for Monday-start weeks
+// and stays unique for any other start day.
+func GetWeekIDForDate(date time.Time, cfg data.Config) string {
+	start, _ := GetWeekBoundsForDate(date, cfg)
+	year, week := start.AddDate(0, 0, 3).ISOWeek()
 	return fmt.Sprintf("%d-%02d", year, week)
 }
 
 // GetCurrentWeekID returns the week ID for the current time.
-func GetCurrentWeekID() string {
-	return GetWeekID(time.Now())
+func GetCurrentWeekID(cfg data.Config) string {
+	return GetWeekID(Now(cfg), cfg)
+}
+
+// WeekStartFromID returns the first date of the week identified by weekID (e.g., "2024-23").
+func WeekStartFromID(weekID string, cfg data.Config) (time.Time, error) {
+	year, weekNum := 0, 0
+	if _, err := fmt.Sscanf(weekID, "%d-%d", &year, &weekNum); err != nil || weekNum < 1 || weekNum > 53 {
+		return time.Time{}, fmt.Errorf("invalid week ID: '%s'. Use the form YYYY-WW", weekID)
+	}
+
+	// January 4th is always in ISO week 1; step back to its Monday and forward to the requested week
+	jan4 := time.Date(year, 1, 4, 0, 0, 0, 0, Location(cfg))
+	monday := jan4.AddDate(0, 0, -((int(jan4.Weekday()) + 6) % 7))
+	monday = monday.AddDate(0, 0, (weekNum-1)*7)
+
+	// Exactly one configured week has its middle day inside this ISO week
+	startDay := WeekStartDay(cfg)
+	for i := 0; i < 7; i++ {
+		start := monday.AddDate(0, 0, i-3)
+		if start.Weekday() == startDay {
+			if GetWeekIDForDate(start, cfg) != weekID {
+				return time.Time{}, fmt.Errorf("invalid week ID: '%s'", weekID)
+			}
+			return start, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid week ID: '%s'", weekID)
 }
 
 // GetDayLogs retrieves the logs for a specific date string (YYYY-MM-DD).
@@ -51,10 +255,10 @@ func GetDayLogs(state *data.AppState, dateStr string) (*data.Day, bool) {
 	return nil, false
 }
 
-// GetOrCreateDayLogs finds or creates a Day struct for the given date.
-// Ensures the Logs slice is sorted chronologically by date.
-func GetOrCreateDayLogs(state *data.AppState, date time.Time) *data.Day {
-	dateStr := date.Format(data.DateFormat)
+// GetOrCreateDayLogs finds or creates the Day struct that a timestamp belongs to,
+// honoring the configured day rollover. Ensures the Logs slice is sorted chronologically by date.
+func GetOrCreateDayLogs(state *data.AppState, timestamp time.Time) *data.Day {
+	dateStr := LogicalDay(timestamp, state.Config).Format(data.DateFormat)
 
 	// Check if the day already exists
 	day, found := GetDayLogs(state, dateStr)
@@ -0,0 +1,63 @@
+package timeutil
+
+import (
+	"testing"
+	"time"
+
+	"grain/internal/data"
+)
+
+func TestWeekIDRoundTrip(t *testing.T) {
+	for _, zone := range []string{"UTC", "Europe/Berlin", "America/Sao_Paulo"} {
+		for startDay := time.Sunday; startDay <= time.Saturday; startDay++ {
+			cfg := data.Config{WeekStart: startDay.String(), Timezone: zone}
+			t.Run(zone+"/"+startDay.String(), func(t *testing.T) {
+				loc := Location(cfg)
+				last := time.Date(2031, 12, 31, 0, 0, 0, 0, loc)
+				seen := map[string]string{}
+				for day := time.Date(2019, 1, 1, 0, 0, 0, 0, loc); !day.After(last); day = day.AddDate(0, 0, 1) {
+					id := GetWeekIDForDate(day, cfg)
+					wantStart, _ := GetWeekBoundsForDate(day, cfg)
+					start, err := WeekStartFromID(id, cfg)
+					if err != nil {
+						t.Fatalf("%s: WeekStartFromID(%q): %v", day.Format(data.DateFormat), id, err)
+					}
+					if !start.Equal(wantStart) || start.Weekday() != startDay {
+						t.Fatalf("%s: week %s starts %s, want %s", day.Format(data.DateFormat), id, start.Format(data.DateFormat), wantStart.Format(data.DateFormat))
+					}
+					// Each ID names exactly one week
+					if other, ok := seen[id]; ok && other != wantStart.Format(data.DateFormat) {
+						t.Fatalf("week ID %s used for weeks starting %s and %s", id, other, wantStart.Format(data.DateFormat))
+					}
+					seen[id] = wantStart.Format(data.DateFormat)
+				}
+			})
+		}
+	}
+}
+
+func TestWeekIDMatchesISOForMondayWeeks(t *testing.T) {
+	cfg := data.Config{WeekStart: "monday", Timezone: "UTC"}
+	tests := map[string]string{
+		"2020-12-31": "2020-53",
+		"2021-01-03": "2020-53",
+		"2021-01-04": "2021-01",
+		"2024-12-30": "2025-01",
+		"2026-10-18": "2026-42",
+	}
+	for date, want := range tests {
+		day, _ := ParseDate(date, cfg)
+		if got := GetWeekIDForDate(day, cfg); got != want {
+			t.Errorf("GetWeekIDForDate(%s) = %s, want %s", date, got, want)
+		}
+	}
+}
+
+func TestWeekStartFromIDErrors(t *testing.T) {
+	cfg := data.Config{WeekStart: "monday", Timezone: "UTC"}
+	for _, id := range []string{"", "2026", "2026-00", "2026-54", "2021-53", "week-12"} {
+		if start, err := WeekStartFromID(id, cfg); err == nil {
+			t.Errorf("WeekStartFromID(%q) = %s, expected an error", id, start.Format(data.DateFormat))
+		}
+	}
+}